@@ -0,0 +1,247 @@
+package trader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+const (
+	accountEndpoint        = "/v3/accounts/{accountID}"
+	accountSummaryEndpoint = "/v3/accounts/{accountID}/summary"
+)
+
+type PositionMode string
+
+const (
+	NettingMode PositionMode = "NETTING"
+	HedgingMode PositionMode = "HEDGING"
+)
+
+var ErrInvalidForPositionMode = errors.New("operation not valid for the account's position mode")
+
+type AccountResponse struct {
+	Account           Account `json:"account"`
+	LastTransactionID string  `json:"lastTransactionID"`
+}
+
+type AccountSummaryResponse struct {
+	Account           AccountSummary `json:"account"`
+	LastTransactionID string         `json:"lastTransactionID"`
+}
+
+type Account struct {
+	AccountSummary
+}
+
+// AccountSummary holds the account's balances and margin figures without
+// its trades, positions and orders.
+type AccountSummary struct {
+	ID              string  `json:"id"`
+	Alias           string  `json:"alias"`
+	Currency        string  `json:"currency"`
+	Balance         float64 `json:"balance,string"`
+	NAV             float64 `json:"NAV,string"`
+	UnrealizedPL    float64 `json:"unrealizedPL,string"`
+	MarginUsed      float64 `json:"marginUsed,string"`
+	MarginAvailable float64 `json:"marginAvailable,string"`
+	MarginRate      float64 `json:"marginRate,string"`
+
+	// MarginCloseoutPercent is a fraction; OANDA starts closing positions
+	// once it reaches 1.
+	MarginCloseoutPercent float64 `json:"marginCloseoutPercent,string"`
+	MarginCloseoutNAV     float64 `json:"marginCloseoutNAV,string"`
+
+	HedgingEnabled    bool   `json:"hedgingEnabled"`
+	OpenTradeCount    int    `json:"openTradeCount"`
+	OpenPositionCount int    `json:"openPositionCount"`
+	PendingOrderCount int    `json:"pendingOrderCount"`
+	LastTransactionID string `json:"lastTransactionID"`
+}
+
+func (c *Client) getAccount() (*AccountResponse, error) {
+	var accountResponse AccountResponse
+	err := c.do(context.Background(), "GET", accountEndpoint, nil, nil, 200, &accountResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.positionMode = accountResponse.Account.positionMode()
+	c.homeCurrency = accountResponse.Account.Currency
+	c.accountMarginRate = accountResponse.Account.MarginRate
+	c.mu.Unlock()
+	return &accountResponse, nil
+}
+
+func (c *Client) getAccountSummary() (*AccountSummaryResponse, error) {
+	var summaryResponse AccountSummaryResponse
+	err := c.do(context.Background(), "GET", accountSummaryEndpoint, nil, nil, 200, &summaryResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.positionMode = summaryResponse.Account.positionMode()
+	c.homeCurrency = summaryResponse.Account.Currency
+	c.accountMarginRate = summaryResponse.Account.MarginRate
+	c.mu.Unlock()
+	return &summaryResponse, nil
+}
+
+func (a *AccountSummary) positionMode() PositionMode {
+	if a.HedgingEnabled {
+		return HedgingMode
+	}
+	return NettingMode
+}
+
+// PositionMode reports whether the account nets or hedges positions. The
+// mode is fetched with getAccount the first time it is needed and cached.
+func (c *Client) PositionMode() (PositionMode, error) {
+	c.mu.Lock()
+	mode := c.positionMode
+	c.mu.Unlock()
+	if mode != "" {
+		return mode, nil
+	}
+
+	accountResponse, err := c.getAccount()
+	if err != nil {
+		return "", err
+	}
+	return accountResponse.Account.positionMode(), nil
+}
+
+// HomeCurrency returns the currency the account is denominated in, which
+// every P/L, margin and financing amount from OANDA is reported in. It is
+// fetched with getAccountSummary the first time and cached, and refreshed
+// whenever the account or its summary is fetched again. OrderNotional,
+// BreakEvenPrice and LiveUnrealizedPL use it to skip the conversion when an
+// instrument is quoted in the home currency.
+func (c *Client) HomeCurrency() (string, error) {
+	c.mu.Lock()
+	currency := c.homeCurrency
+	c.mu.Unlock()
+	if currency != "" {
+		return currency, nil
+	}
+
+	summaryResponse, err := c.getAccountSummary()
+	if err != nil {
+		return "", err
+	}
+	return summaryResponse.Account.Currency, nil
+}
+
+// quoteIsHome reports whether currency is the account's home currency, in
+// which case amounts in it need no conversion. It is false if the home
+// currency can't be fetched, so callers fall back to OANDA's conversions.
+func (c *Client) quoteIsHome(currency string) bool {
+	home, err := c.HomeCurrency()
+	return err == nil && home == currency
+}
+
+// homeConversion finds the conversion for currency among conversions, or
+// returns factors of 1 when isHome says no conversion is needed.
+func homeConversion(currency string, isHome bool, conversions []HomeConversion) (HomeConversion, error) {
+	if isHome {
+		return HomeConversion{Currency: currency, AccountGain: 1, AccountLoss: 1, PositionValue: 1}, nil
+	}
+	for _, conversion := range conversions {
+		if conversion.Currency == currency {
+			return conversion, nil
+		}
+	}
+	return HomeConversion{}, fmt.Errorf("no home conversion received for %s", currency)
+}
+
+func (c *Client) requirePositionMode(mode PositionMode, operation string) error {
+	current, err := c.PositionMode()
+	if err != nil {
+		return err
+	}
+	if current != mode {
+		return fmt.Errorf("%s requires a %s account: %w", operation, mode, ErrInvalidForPositionMode)
+	}
+	return nil
+}
+
+// MarginCallStatus compares the account's margin closeout percent against a
+// threshold. Both are percentages, and OANDA begins closing positions when
+// CloseoutPercent reaches 100.
+type MarginCallStatus struct {
+	CloseoutPercent float64
+	ThresholdPct    float64
+	Near            bool
+}
+
+func (c *Client) MarginCloseoutPercent() (float64, error) {
+	summaryResponse, err := c.getAccountSummary()
+	if err != nil {
+		return 0, err
+	}
+	return summaryResponse.Account.MarginCloseoutPercent * 100, nil
+}
+
+// IsNearMarginCall reports whether the margin closeout percent has reached
+// thresholdPct, so callers can reduce exposure before OANDA liquidates.
+func (c *Client) IsNearMarginCall(thresholdPct float64) (*MarginCallStatus, error) {
+	if thresholdPct <= 0 || thresholdPct > 100 {
+		return nil, fmt.Errorf("margin call threshold must be between 0 and 100, got %g", thresholdPct)
+	}
+
+	closeoutPercent, err := c.MarginCloseoutPercent()
+	if err != nil {
+		return nil, err
+	}
+	return &MarginCallStatus{
+		CloseoutPercent: closeoutPercent,
+		ThresholdPct:    thresholdPct,
+		Near:            closeoutPercent >= thresholdPct,
+	}, nil
+}
+
+// minAccountSyncInterval is the fastest StartAccountSync polls, so that a
+// dashboard can't spend the account's request allowance on summaries.
+const minAccountSyncInterval = time.Second
+
+// StartAccountSync fetches the account summary every interval in a new
+// goroutine and passes it to onSummary, until ctx is cancelled. Errors go
+// to onError, or are logged when it is nil, and the next fetch is tried on
+// schedule. The interval is measured from the end of each fetch and
+// callback, so a slow request or callback never overlaps the next one.
+// Intervals under a second are raised to a second, and each fetch also
+// waits its turn under WithRateLimit like any other request.
+func (c *Client) StartAccountSync(ctx context.Context, interval time.Duration, onSummary func(AccountSummary), onError func(error)) error {
+	if interval <= 0 {
+		return fmt.Errorf("account sync interval must be positive, got %s", interval)
+	}
+	interval = max(interval, minAccountSyncInterval)
+	if onSummary == nil {
+		return fmt.Errorf("account sync needs a summary callback")
+	}
+
+	go func() {
+		for {
+			summaryResponse, err := c.getAccountSummary()
+			switch {
+			case err == nil:
+				onSummary(summaryResponse.Account)
+			case onError != nil:
+				onError(err)
+			default:
+				log.Printf("Error syncing account summary: %v", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-c.clock.After(interval):
+			}
+		}
+	}()
+	return nil
+}
@@ -1,14 +1,14 @@
 package trader
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"log"
-	"net/http"
+	"net/url"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/davecgh/go-spew/spew"
 )
@@ -25,29 +25,73 @@ type Credentials struct {
 }
 
 type RawPricingResponse struct {
-	Time   string `json:"time"`
-	Prices []struct {
-		Instrument string `json:"instrument"`
-		Tradeable  bool   `json:"tradeable"`
-		Bids       []struct {
-			Price float32 `json:"price,string"`
-		} `json:"bids"`
-		Asks []struct {
-			Price float32 `json:"price,string"`
-		} `json:"asks"`
-	} `json:"prices"`
+	Time            string           `json:"time"`
+	Prices          []RawPrice       `json:"prices"`
+	HomeConversions []HomeConversion `json:"homeConversions"`
+}
+
+type RawPrice struct {
+	Instrument string `json:"instrument"`
+	Time       string `json:"time"`
+	Tradeable  bool   `json:"tradeable"`
+	Bids       []struct {
+		Price float32 `json:"price,string"`
+	} `json:"bids"`
+	Asks []struct {
+		Price float32 `json:"price,string"`
+	} `json:"asks"`
+	CloseoutBid    float32 `json:"closeoutBid,string"`
+	CloseoutAsk    float32 `json:"closeoutAsk,string"`
+	UnitsAvailable *struct {
+		Default UnitsAvailable `json:"default"`
+	} `json:"unitsAvailable"`
 }
 
 type PricingResponse struct {
-	Time   string  `json:"time"`
-	Prices []Price `json:"prices"`
+	Time            string           `json:"time"`
+	Prices          []Price          `json:"prices"`
+	HomeConversions []HomeConversion `json:"homeConversions"`
+}
+
+// HomeConversion holds the factors that convert an amount in Currency into
+// the account's home currency.
+type HomeConversion struct {
+	Currency      string  `json:"currency"`
+	AccountGain   float64 `json:"accountGain,string"`
+	AccountLoss   float64 `json:"accountLoss,string"`
+	PositionValue float64 `json:"positionValue,string"`
 }
 
 type Price struct {
-	Instrument string
-	Tradeable  bool
-	Bid        float32
-	Ask        float32
+	Instrument     string
+	Time           time.Time
+	Tradeable      bool
+	Bid            float32
+	Ask            float32
+	UnitsAvailable *UnitsAvailable
+
+	// CloseoutBid and CloseoutAsk are the prices OANDA values positions at
+	// for margin closeout. They are wider than Bid and Ask, so margin risk
+	// should be measured against them.
+	CloseoutBid float32
+	CloseoutAsk float32
+
+	// Stale is set when the price is a cached last good value returned in
+	// place of a failed request.
+	Stale bool
+}
+
+// UnitsAvailable is the largest order that margin allows for the account's
+// default position fill policy. It is only populated when requested through
+// PricingOptions.
+type UnitsAvailable struct {
+	Long  float64 `json:"long,string"`
+	Short float64 `json:"short,string"`
+}
+
+type PricingOptions struct {
+	IncludeUnitsAvailable  bool
+	IncludeHomeConversions bool
 }
 
 type MarketOrderRequest struct {
@@ -55,19 +99,46 @@ type MarketOrderRequest struct {
 }
 
 type MarketOrder struct {
-	Units        string `json:"units"`
-	Instrument   string `json:"instrument"`
-	PriceBound   string `json:"priceBound"`
-	TimeInForce  string `json:"timeInForce"`
-	Type         string `json:"type"`
-	PositionFill string `json:"positionFill"`
+	Units            string            `json:"units"`
+	Instrument       string            `json:"instrument"`
+	PriceBound       string            `json:"priceBound,omitempty"`
+	TimeInForce      string            `json:"timeInForce"`
+	Type             string            `json:"type"`
+	PositionFill     string            `json:"positionFill"`
+	ClientExtensions *ClientExtensions `json:"clientExtensions,omitempty"`
+	StopLossOnFill   *OnFillDetails    `json:"stopLossOnFill,omitempty"`
+	TakeProfitOnFill *OnFillDetails    `json:"takeProfitOnFill,omitempty"`
+
+	GuaranteedStopLossOnFill *OnFillDetails `json:"guaranteedStopLossOnFill,omitempty"`
+}
+
+// OnFillDetails describes a dependent order to create on the trade an order
+// opens.
+type OnFillDetails struct {
+	Price       string `json:"price,omitempty"`
+	Distance    string `json:"distance,omitempty"`
+	TimeInForce string `json:"timeInForce,omitempty"`
+}
+
+type ClientExtensions struct {
+	ID      string `json:"id,omitempty"`
+	Tag     string `json:"tag,omitempty"`
+	Comment string `json:"comment,omitempty"`
 }
 
 type OrderResponse struct {
-	LastTransactionID      string                 `json:"lastTransactionID"`
-	OrderCreateTransaction OrderCreateTransaction `json:"orderCreateTransaction"`
-	OrderFillTransaction   OrderFillTransaction   `json:"orderFillTransaction"`
-	RelatedTransactionIDs  []string               `json:"relatedTransactionIDs"`
+	LastTransactionID      string                        `json:"lastTransactionID"`
+	OrderCreateTransaction OrderCreateTransaction        `json:"orderCreateTransaction"`
+	OrderFillTransaction   OrderFillTransaction          `json:"orderFillTransaction"`
+	OrderCancelTransaction *OrderCancelTransaction       `json:"orderCancelTransaction"`
+	OrderRejectTransaction *MarketOrderRejectTransaction `json:"orderRejectTransaction"`
+	RelatedTransactionIDs  []string                      `json:"relatedTransactionIDs"`
+	ErrorCode              string                        `json:"errorCode"`
+	ErrorMessage           string                        `json:"errorMessage"`
+
+	// Transactions holds every transaction in the response, including
+	// types without a field above.
+	Transactions []Transaction `json:"-"`
 }
 
 type OrderCreateTransaction struct {
@@ -85,174 +156,297 @@ type OrderCreateTransaction struct {
 }
 
 type OrderFillTransaction struct {
-	AccountBalance string      `json:"accountBalance"`
-	AccountID      string      `json:"accountID"`
-	BatchID        string      `json:"batchID"`
-	Financing      string      `json:"financing"`
-	ID             string      `json:"id"`
-	Instrument     string      `json:"instrument"`
-	OrderID        string      `json:"orderID"`
-	Pl             string      `json:"pl"`
-	Price          string      `json:"price"`
-	Reason         string      `json:"reason"`
-	Time           string      `json:"time"`
-	TradeOpened    TradeOpened `json:"tradeOpened"`
-	Type           string      `json:"type"`
-	Units          string      `json:"units"`
-	UserID         int         `json:"userID"`
+	AccountBalance string        `json:"accountBalance"`
+	AccountID      string        `json:"accountID"`
+	BatchID        string        `json:"batchID"`
+	Financing      string        `json:"financing"`
+	ID             string        `json:"id"`
+	Instrument     string        `json:"instrument"`
+	OrderID        string        `json:"orderID"`
+	Pl             string        `json:"pl"`
+	Price          string        `json:"price"`
+	Reason         string        `json:"reason"`
+	Time           string        `json:"time"`
+	TradeOpened    TradeOpened   `json:"tradeOpened"`
+	TradeReduced   *TradeReduce  `json:"tradeReduced"`
+	TradesClosed   []TradeReduce `json:"tradesClosed"`
+	Type           string        `json:"type"`
+	Units          string        `json:"units"`
+	UserID         int           `json:"userID"`
+
+	GuaranteedExecutionFee string `json:"guaranteedExecutionFee"`
 }
 
 type TradeOpened struct {
 	TradeID string `json:"tradeID"`
 	Units   string `json:"units"`
+	Price   string `json:"price"`
+
+	GuaranteedExecutionFee string `json:"guaranteedExecutionFee"`
 }
 
-func getCreds() *Credentials {
+type TradeReduce struct {
+	TradeID    string `json:"tradeID"`
+	Units      string `json:"units"`
+	Price      string `json:"price"`
+	RealizedPL string `json:"realizedPL"`
+	Financing  string `json:"financing"`
+}
+
+// config is the layout of config.json: the credentials plus optional named
+// watchlists of instruments.
+type config struct {
+	Credentials
+	Watchlists map[string][]string `json:"watchlists"`
+}
+
+func getConfig() *config {
 	file, err := os.Open("config.json")
 	if err != nil {
 		log.Fatal(err)
 	}
 	defer file.Close()
 
-	var creds Credentials
+	var cfg config
 	decoder := json.NewDecoder(file)
-	err = decoder.Decode(&creds)
+	err = decoder.Decode(&cfg)
 	if err != nil {
 		log.Fatal((err))
 	}
 
-	return &creds
+	return &cfg
+}
+
+func getCreds() *Credentials {
+	return &getConfig().Credentials
 }
 
 func parseRawResponse(rawResponse *RawPricingResponse) (*PricingResponse, error) {
 	response := PricingResponse{
-		Time:   rawResponse.Time,
-		Prices: make([]Price, len(rawResponse.Prices)),
+		Time:            rawResponse.Time,
+		Prices:          make([]Price, len(rawResponse.Prices)),
+		HomeConversions: rawResponse.HomeConversions,
 	}
 
 	for i, rawPrice := range rawResponse.Prices {
-		price := Price{
-			Instrument: rawPrice.Instrument,
-			Tradeable:  rawPrice.Tradeable,
-		}
-
-		if len(rawPrice.Bids) > 0 {
-			price.Bid = rawPrice.Bids[0].Price
-		} else {
-			return nil, fmt.Errorf("No bid prices recieved.")
-		}
-		if len(rawPrice.Asks) > 0 {
-			price.Ask = rawPrice.Asks[0].Price
-		} else {
-			return nil, fmt.Errorf("No ask prices recieved.")
+		price, err := parseRawPrice(&rawPrice)
+		if err != nil {
+			return nil, err
 		}
-
-		response.Prices[i] = price
+		response.Prices[i] = *price
 	}
 
 	return &response, nil
 }
 
-func getPrices(instruments []string) (*PricingResponse, error) {
-	creds := getCreds()
-	url := strings.Replace(baseURL+pricingEndpoint, "{accountID}", creds.AccountID, 1)
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, err
+func parseRawPrice(rawPrice *RawPrice) (*Price, error) {
+	price := Price{
+		Instrument: rawPrice.Instrument,
+		Tradeable:  rawPrice.Tradeable,
 	}
 
-	req.Header.Add("Authorization", "Bearer "+creds.BearerToken)
-	q := req.URL.Query()
-	q.Add("instruments", strings.Join(instruments, ","))
-	req.URL.RawQuery = q.Encode()
+	if rawPrice.Time != "" {
+		t, err := time.Parse(time.RFC3339Nano, rawPrice.Time)
+		if err != nil {
+			return nil, fmt.Errorf("invalid price time %q: %w", rawPrice.Time, err)
+		}
+		price.Time = t
+	}
+	if len(rawPrice.Bids) > 0 {
+		price.Bid = rawPrice.Bids[0].Price
+	} else {
+		return nil, fmt.Errorf("No bid prices recieved.")
+	}
+	if len(rawPrice.Asks) > 0 {
+		price.Ask = rawPrice.Asks[0].Price
+	} else {
+		return nil, fmt.Errorf("No ask prices recieved.")
+	}
+	price.CloseoutBid, price.CloseoutAsk = rawPrice.CloseoutBid, rawPrice.CloseoutAsk
+	if price.CloseoutBid == 0 {
+		price.CloseoutBid = price.Bid
+	}
+	if price.CloseoutAsk == 0 {
+		price.CloseoutAsk = price.Ask
+	}
+	if rawPrice.UnitsAvailable != nil {
+		unitsAvailable := rawPrice.UnitsAvailable.Default
+		price.UnitsAvailable = &unitsAvailable
+	}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, err
+	return &price, nil
+}
+
+func (c *Client) getPrices(instruments []string, opts PricingOptions) (*PricingResponse, error) {
+	if c.restrictPricing {
+		for _, instrument := range instruments {
+			if err := c.checkInstrumentAllowed(instrument); err != nil {
+				return nil, err
+			}
+		}
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
+	cacheable := !opts.IncludeUnitsAvailable && !opts.IncludeHomeConversions
+	if cacheable && c.priceCache.ttl > 0 {
+		if prices, ok := c.priceCache.lookup(instruments, c.clock.Now(), c.priceCache.ttl); ok {
+			return &PricingResponse{Prices: prices}, nil
+		}
 	}
-	if resp.StatusCode != 200 {
-		err := fmt.Errorf("%d response code received, Prices API request not working as expected", resp.StatusCode)
-		return nil, err
+
+	q := url.Values{}
+	q.Add("instruments", strings.Join(instruments, ","))
+	if opts.IncludeUnitsAvailable {
+		q.Add("includeUnitsAvailable", "true")
+	}
+	if opts.IncludeHomeConversions {
+		q.Add("includeHomeConversions", "true")
 	}
 
 	var rawResponse RawPricingResponse
-	err = json.Unmarshal(body, &rawResponse)
+	err := c.do(context.Background(), "GET", pricingEndpoint, q, nil, 200, &rawResponse)
 	if err != nil {
+		if c.stalePriceFallback && cacheable && isTransient(err) {
+			if prices, ok := c.priceCache.lookup(instruments, c.clock.Now(), 0); ok {
+				for i := range prices {
+					prices[i].Stale = true
+				}
+				return &PricingResponse{Prices: prices}, nil
+			}
+		}
 		return nil, err
 	}
 	response, err := parseRawResponse(&rawResponse)
 	if err != nil {
 		return nil, err
 	}
+	c.priceCache.store(response.Prices, c.clock.Now())
 	return response, nil
 }
 
-func placeMarketOrder(units int, instrument string, priceBound float32) (*OrderResponse, error) {
-	creds := getCreds()
-	url := strings.Replace(baseURL+orderEndpoint, "{accountID}", creds.AccountID, 1)
+// GetPrice returns the current price of a single instrument, or an error if
+// OANDA didn't return one or the instrument can't be traded right now.
+func (c *Client) GetPrice(instrument string) (Price, error) {
+	price, err := c.quote(instrument)
+	if err != nil {
+		return Price{}, err
+	}
+	if !price.Tradeable {
+		return *price, fmt.Errorf("%w: %s", ErrInstrumentNotTradeable, instrument)
+	}
+	return *price, nil
+}
+
+// GetPrices returns the current prices of instruments.
+func (c *Client) GetPrices(instruments []string) (*PricingResponse, error) {
+	return c.getPrices(instruments, PricingOptions{})
+}
+
+// PlaceMarketOrder is placeMarketOrder for callers outside the package.
+func (c *Client) PlaceMarketOrder(units int, instrument string, priceBound float32) (*OrderResponse, error) {
+	return c.placeMarketOrder(units, instrument, priceBound)
+}
+
+// placeMarketOrder fills units at the market or not at all. If the price
+// has moved past priceBound the order is cancelled instead. Pass
+// NoPriceBound to send the order without a bound, which guarantees a fill
+// whenever the market is open at the cost of taking whatever price is
+// available, however far it has moved.
+func (c *Client) placeMarketOrder(units int, instrument string, priceBound float32) (*OrderResponse, error) {
+	return c.submitMarketOrder(MarketOrder{
+		Units:        fmt.Sprintf("%d", units),
+		Instrument:   instrument,
+		PriceBound:   c.formatPriceBound(instrument, units, priceBound),
+		TimeInForce:  "FOK",
+		Type:         "MARKET",
+		PositionFill: "DEFAULT",
+	}, units)
+}
+
+// maxTolerancePips caps placeMarketOrderWithTolerance, beyond which a
+// tolerance is more likely a units mix-up than a deliberate choice.
+const maxTolerancePips = 100
 
-	orderRequest := MarketOrderRequest{
-		Order: MarketOrder{
-			Units:        fmt.Sprintf("%d", units),
-			Instrument:   instrument,
-			PriceBound:   fmt.Sprintf("%.5f", priceBound),
-			TimeInForce:  "FOK",
-			Type:         "MARKET",
-			PositionFill: "DEFAULT",
-		},
+// placeMarketOrderWithTolerance places a market order that fills only within
+// tolerancePips of the current quote: a buy up to the ask plus the
+// tolerance and a sell down to the bid minus it.
+func (c *Client) placeMarketOrderWithTolerance(units int, instrumentName string, tolerancePips float64) (*OrderResponse, error) {
+	if tolerancePips <= 0 || tolerancePips > maxTolerancePips {
+		return nil, fmt.Errorf("price tolerance must be between 0 and %d pips, got %g", maxTolerancePips, tolerancePips)
 	}
 
-	jsonBody, err := json.Marshal(orderRequest)
+	instrument, err := c.instrument(instrumentName)
 	if err != nil {
 		return nil, err
 	}
-
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonBody))
+	quote, err := c.quote(instrumentName)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+creds.BearerToken)
+	tolerance := tolerancePips * instrument.PipSize()
+	priceBound := float64(quote.Ask) + tolerance
+	if units < 0 {
+		priceBound = float64(quote.Bid) - tolerance
+	}
+	return c.placeMarketOrder(units, instrumentName, float32(priceBound))
+}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
+// placeIOCMarketOrder fills as much of units as is available at priceBound
+// or better and cancels the rest, so the fill may be partial; see
+// OrderResponse.WasPartial.
+func (c *Client) placeIOCMarketOrder(units int, instrument string, priceBound float32) (*OrderResponse, error) {
+	return c.submitMarketOrder(MarketOrder{
+		Units:        fmt.Sprintf("%d", units),
+		Instrument:   instrument,
+		PriceBound:   c.formatPriceBound(instrument, units, priceBound),
+		TimeInForce:  "IOC",
+		Type:         "MARKET",
+		PositionFill: "DEFAULT",
+	}, units)
+}
+
+func (c *Client) submitMarketOrder(order MarketOrder, units int) (*OrderResponse, error) {
+	defer c.instrumentLocks.lock(order.Instrument)()
+
+	if err := c.checkOrder(order, units); err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
+	return c.sendMarketOrder(order, units)
+}
 
-	body, err := io.ReadAll(resp.Body)
+// sendMarketOrder submits order without any pre-trade checks. The caller
+// must hold the instrument's order lock.
+func (c *Client) sendMarketOrder(order MarketOrder, units int) (*OrderResponse, error) {
+	idempotencyKey, err := newIdempotencyKey()
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.StatusCode != 201 {
-		return nil, fmt.Errorf("unexpected status code: %d, body: %s",
-			resp.StatusCode,
-			string(body))
+	if order.ClientExtensions == nil {
+		order.ClientExtensions = &ClientExtensions{}
 	}
+	order.ClientExtensions.ID = idempotencyKey
+	orderRequest := MarketOrderRequest{Order: order}
 
 	var orderResponse OrderResponse
-	err = json.Unmarshal(body, &orderResponse)
+	release := c.acquireOrderSlot()
+	err = c.do(context.Background(), "POST", orderEndpoint, nil, orderRequest, 201, &orderResponse)
+	release()
 	if err != nil {
-		return nil, err
+		return nil, c.classifyOrderError(err)
 	}
+	c.classifyOrderCancel(&orderResponse)
 
+	c.auditOrder(&orderResponse, order.Instrument, units, idempotencyKey)
 	return &orderResponse, nil
 }
 
 func EntryPoint() {
+	client := NewClient()
+
 	// Example usage of getPrices
 	instruments := []string{"GBP_USD", "EUR_GBP", "GBP_JPY"}
-	pricesResponse, err := getPrices(instruments)
+	pricesResponse, err := client.getPrices(instruments, PricingOptions{})
 	if err != nil {
 		log.Fatalf("Error retrieving prices: %v", err)
 	} else {
@@ -263,7 +457,7 @@ func EntryPoint() {
 
 	// Example usage of placeMarketOrder
 	if pricesResponse.Prices[0].Tradeable {
-		orderResponse, err := placeMarketOrder(1, "GBP_USD", pricesResponse.Prices[0].Ask)
+		orderResponse, err := client.placeMarketOrder(1, "GBP_USD", pricesResponse.Prices[0].Ask)
 		if err != nil {
 			log.Printf("Error placing market order: %v", err)
 		} else {
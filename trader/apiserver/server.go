@@ -0,0 +1,181 @@
+// Package apiserver serves a trader.Client over HTTP so services written in
+// other languages can use it. It lives outside package trader so library
+// users don't pull in an HTTP server they don't need.
+package apiserver
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/chitraksen/alGotrade/trader"
+)
+
+const (
+	maxRequestSize = 1 << 16
+	maxInstruments = 50
+)
+
+var instrumentPattern = regexp.MustCompile(`^[A-Z0-9]+_[A-Z0-9]+$`)
+
+// Server is an http.Handler exposing:
+//
+//	GET  /prices?instruments=EUR_USD,USD_JPY
+//	POST /orders     {"instrument": "EUR_USD", "units": 100, "priceBound": 1.1}
+//	GET  /positions
+//
+// Every request must carry "Authorization: Bearer <token>". Responses are
+// JSON, with errors as {"error": "..."}.
+type Server struct {
+	client *trader.Client
+	token  string
+	mux    *http.ServeMux
+}
+
+// OrderRequest is the body of POST /orders. A zero PriceBound sends the order
+// without a bound.
+type OrderRequest struct {
+	Instrument string  `json:"instrument"`
+	Units      int     `json:"units"`
+	PriceBound float32 `json:"priceBound"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// NewAPIServer returns a Server for client that accepts only requests
+// bearing token. It panics if token is empty, since that would leave order
+// placement open to anyone who can reach the server.
+func NewAPIServer(client *trader.Client, token string) *Server {
+	if token == "" {
+		panic("apiserver: token must not be empty")
+	}
+
+	s := &Server{client: client, token: token, mux: http.NewServeMux()}
+	s.mux.HandleFunc("GET /prices", s.handlePrices)
+	s.mux.HandleFunc("POST /orders", s.handleOrders)
+	s.mux.HandleFunc("GET /positions", s.handlePositions)
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		writeError(w, http.StatusUnauthorized, errors.New("missing or invalid token"))
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) == 1
+}
+
+func (s *Server) handlePrices(w http.ResponseWriter, r *http.Request) {
+	param := r.URL.Query().Get("instruments")
+	if param == "" {
+		writeError(w, http.StatusBadRequest, errors.New("instruments query parameter is required"))
+		return
+	}
+	instruments := strings.Split(param, ",")
+	if len(instruments) > maxInstruments {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("at most %d instruments can be priced at once", maxInstruments))
+		return
+	}
+	for _, instrument := range instruments {
+		if err := validateInstrument(instrument); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	prices, err := s.client.GetPrices(instruments)
+	if err != nil {
+		writeClientError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, prices)
+}
+
+func (s *Server) handleOrders(w http.ResponseWriter, r *http.Request) {
+	var order OrderRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&order); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid order: %w", err))
+		return
+	}
+	if err := validateInstrument(order.Instrument); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if order.Units == 0 {
+		writeError(w, http.StatusBadRequest, errors.New("units must not be zero"))
+		return
+	}
+	if order.PriceBound < 0 {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("price bound must not be negative, got %g", order.PriceBound))
+		return
+	}
+
+	response, err := s.client.PlaceMarketOrder(order.Units, order.Instrument, order.PriceBound)
+	if err != nil {
+		writeClientError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, response)
+}
+
+func (s *Server) handlePositions(w http.ResponseWriter, r *http.Request) {
+	positions, err := s.client.GetOpenPositions()
+	if err != nil {
+		writeClientError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, positions)
+}
+
+func validateInstrument(instrument string) error {
+	if !instrumentPattern.MatchString(instrument) {
+		return fmt.Errorf("invalid instrument %q, expected a name like EUR_USD", instrument)
+	}
+	return nil
+}
+
+// writeClientError maps an error from the Client to a status. Orders the
+// broker or our own checks refused are the caller's to fix; anything else is
+// reported as a failure upstream.
+func writeClientError(w http.ResponseWriter, err error) {
+	var rejection *trader.RejectionError
+	var apiErr *trader.APIError
+	switch {
+	case errors.Is(err, trader.ErrTradingHalted):
+		writeError(w, http.StatusServiceUnavailable, err)
+	case errors.As(err, &rejection):
+		writeError(w, http.StatusUnprocessableEntity, err)
+	case errors.As(err, &apiErr) && (apiErr.StatusCode == 400 || apiErr.StatusCode == 404):
+		writeError(w, http.StatusUnprocessableEntity, err)
+	default:
+		log.Printf("Error serving request: %v", err)
+		writeError(w, http.StatusBadGateway, err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Error writing response: %v", err)
+	}
+}
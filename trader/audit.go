@@ -0,0 +1,107 @@
+package trader
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// AuditRecord describes a successfully placed order. IdempotencyKey is the
+// client extension ID the order was submitted with.
+type AuditRecord struct {
+	Time           time.Time `json:"time"`
+	Instrument     string    `json:"instrument"`
+	Units          int       `json:"units"`
+	FillPrice      float32   `json:"fillPrice"`
+	OrderID        string    `json:"orderID"`
+	TransactionIDs []string  `json:"transactionIDs"`
+	IdempotencyKey string    `json:"idempotencyKey"`
+}
+
+// AuditSink receives a record of every order the Client places. Errors are
+// logged and never fail the order, which has already been accepted by OANDA.
+type AuditSink interface {
+	WriteOrder(record AuditRecord) error
+}
+
+type NopAuditSink struct{}
+
+func (NopAuditSink) WriteOrder(AuditRecord) error { return nil }
+
+// WriterAuditSink writes each record to w as a line of JSON.
+type WriterAuditSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewWriterAuditSink(w io.Writer) *WriterAuditSink {
+	return &WriterAuditSink{w: w}
+}
+
+func (s *WriterAuditSink) WriteOrder(record AuditRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.w.Write(append(line, '\n'))
+	return err
+}
+
+func (c *Client) auditOrder(orderResponse *OrderResponse, instrument string, units int, idempotencyKey string) {
+	record := AuditRecord{
+		Time:           c.clock.Now(),
+		Instrument:     instrument,
+		Units:          units,
+		OrderID:        orderResponse.OrderCreateTransaction.ID,
+		TransactionIDs: orderResponse.RelatedTransactionIDs,
+		IdempotencyKey: idempotencyKey,
+	}
+	if fill := orderResponse.OrderFillTransaction; fill.Price != "" {
+		price, err := strconv.ParseFloat(fill.Price, 32)
+		if err != nil {
+			log.Printf("Error parsing fill price for audit record: %v", err)
+		}
+		record.FillPrice = float32(price)
+	}
+
+	if err := c.auditSink.WriteOrder(record); err != nil {
+		log.Printf("Error writing order audit record: %v", err)
+	}
+
+	if fill := orderResponse.OrderFillTransaction; fill.ID != "" {
+		for _, hook := range c.postFillHooks {
+			if err := hook(fill, idempotencyKey); err != nil {
+				log.Printf("Error in post-fill hook for %s: %v", fill.ID, err)
+			}
+		}
+	}
+}
+
+// WithPostFillHook calls hook with the fill and idempotency key of every
+// order that fills, after it is audited. The order has already been
+// accepted, so an error from hook is logged and never fails it. Hooks run
+// on the goroutine that placed the order, in the order they were added,
+// while the instrument is still locked for the order, so a hook must not
+// place another order in the same instrument; hand that off to another
+// goroutine instead.
+func WithPostFillHook(hook func(fill OrderFillTransaction, idempotencyKey string) error) Option {
+	return func(c *Client) {
+		c.postFillHooks = append(c.postFillHooks, hook)
+	}
+}
+
+func newIdempotencyKey() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,94 @@
+package trader
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	// backfillPageInterval is the least time between the page requests of
+	// a backfill, on top of any WithRateLimit.
+	backfillPageInterval = 100 * time.Millisecond
+
+	// backfillRateLimitWait is how long a backfill waits after a 429
+	// before asking for the page again, doubling on each further 429 up to
+	// backfillMaxRateLimitRetries times in a row.
+	backfillRateLimitWait       = time.Second
+	backfillMaxRateLimitRetries = 5
+)
+
+// BackfillProgress is reported after each page of a backfill.
+type BackfillProgress struct {
+	Fetched int
+	Through time.Time
+}
+
+// BackfillCandles fetches every complete candle of instrument from from up
+// to but excluding to, paging past OANDA's per-request limit. Each page
+// starts at the last candle of the previous one, and that repeated boundary
+// candle is dropped.
+//
+// Pages are requested at least 100ms apart, and further apart if the Client
+// has a WithRateLimit. A page answered with 429 Too Many Requests is asked
+// for again after a second, doubling the wait each time, and the backfill
+// only fails after five 429s in a row. Other errors end it straight away
+// with the candles fetched so far.
+func (c *Client) BackfillCandles(instrument, granularity string, from, to time.Time) ([]Candle, error) {
+	return c.BackfillCandlesWithProgress(instrument, granularity, from, to, nil)
+}
+
+// BackfillCandlesWithProgress is BackfillCandles calling progress, if not
+// nil, after each page.
+func (c *Client) BackfillCandlesWithProgress(instrument, granularity string, from, to time.Time, progress func(BackfillProgress)) ([]Candle, error) {
+	if !from.Before(to) {
+		return nil, fmt.Errorf("backfill from %s must be before to %s", from, to)
+	}
+
+	var candles []Candle
+	next := from
+	rateLimited := 0
+	for page := 0; next.Before(to); page++ {
+		if page > 0 {
+			<-c.clock.After(backfillPageInterval)
+		}
+		candlesResponse, err := c.getCandles(instrument, CandleOptions{
+			Granularity: granularity,
+			From:        next,
+			Count:       maxCandleCount,
+		})
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusTooManyRequests && rateLimited < backfillMaxRateLimitRetries {
+			<-c.clock.After(backfillRateLimitWait << rateLimited)
+			rateLimited++
+			continue
+		}
+		if err != nil {
+			return candles, fmt.Errorf("backfilling %s from %s: %w", instrument, next, err)
+		}
+		rateLimited = 0
+
+		added := 0
+		for _, candle := range candlesResponse.Candles {
+			if !candle.Time.Before(to) {
+				break
+			}
+			if len(candles) > 0 && !candle.Time.After(candles[len(candles)-1].Time) {
+				continue
+			}
+			candles = append(candles, candle)
+			added++
+		}
+		if added == 0 {
+			break
+		}
+		next = candles[len(candles)-1].Time
+
+		if progress != nil {
+			progress(BackfillProgress{Fetched: len(candles), Through: next})
+		}
+	}
+
+	return candles, nil
+}
@@ -0,0 +1,68 @@
+package trader
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBackfillRetriesRateLimitedPages(t *testing.T) {
+	start := time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC)
+	last := start.Add(5 * time.Minute)
+
+	var requests atomic.Int64
+	doer := doerFunc(func(req *http.Request) (*http.Response, error) {
+		if !strings.HasSuffix(req.URL.Path, "/candles") {
+			return jsonResponse(404, `{"errorMessage": "not found"}`), nil
+		}
+		if requests.Add(1) == 2 {
+			return jsonResponse(429, `{"errorMessage": "too many requests"}`), nil
+		}
+		from, err := time.Parse(time.RFC3339, req.URL.Query().Get("from"))
+		if err != nil {
+			return nil, err
+		}
+		var candles []string
+		for t := from; !t.After(last) && t.Before(from.Add(3*time.Minute)); t = t.Add(time.Minute) {
+			candles = append(candles, fmt.Sprintf(`{"complete": true, "time": %q, "volume": 1,
+				"mid": {"o": "1.1", "h": "1.1", "l": "1.1", "c": "1.1"}}`, t.Format(time.RFC3339)))
+		}
+		return jsonResponse(200, `{"instrument": "EUR_USD", "granularity": "M1", "candles": [`+strings.Join(candles, ",")+`]}`), nil
+	})
+
+	clock := NewFakeClock(start.Add(time.Hour))
+	c := newTestClient(doer, WithClock(clock))
+
+	type result struct {
+		candles []Candle
+		err     error
+	}
+	done := make(chan result)
+	go func() {
+		candles, err := c.BackfillCandles("EUR_USD", "M1", start, start.Add(time.Hour))
+		done <- result{candles, err}
+	}()
+
+	for {
+		select {
+		case r := <-done:
+			if r.err != nil {
+				t.Fatal(r.err)
+			}
+			if len(r.candles) != 6 {
+				t.Fatalf("got %d candles, want 6", len(r.candles))
+			}
+			for i, candle := range r.candles {
+				if want := start.Add(time.Duration(i) * time.Minute); !candle.Time.Equal(want) {
+					t.Errorf("candle %d at %s, want %s", i, candle.Time, want)
+				}
+			}
+			return
+		case <-time.After(time.Millisecond):
+			clock.Advance(100 * time.Millisecond)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+// barSettleDelay is how long after a bar's scheduled close the scheduler
+// waits before fetching it, allowing for clock skew and OANDA finalising
+// the candle.
+const barSettleDelay = 2 * time.Second
+
+var granularityDurations = map[string]time.Duration{
+	"S5": 5 * time.Second, "S10": 10 * time.Second, "S15": 15 * time.Second, "S30": 30 * time.Second,
+	"M1": time.Minute, "M2": 2 * time.Minute, "M4": 4 * time.Minute, "M5": 5 * time.Minute,
+	"M10": 10 * time.Minute, "M15": 15 * time.Minute, "M30": 30 * time.Minute,
+	"H1": time.Hour, "H2": 2 * time.Hour, "H3": 3 * time.Hour, "H4": 4 * time.Hour,
+	"H6": 6 * time.Hour, "H8": 8 * time.Hour, "H12": 12 * time.Hour,
+	"D": 24 * time.Hour, "W": 7 * 24 * time.Hour,
+}
+
+// BarScheduler calls back once for every completed candle of an instrument
+// and granularity, shortly after OANDA closes it. Bar boundaries come from
+// the candles themselves rather than the local clock, so they follow
+// OANDA's alignment and a skewed clock only delays delivery.
+type BarScheduler struct {
+	client      *Client
+	instrument  string
+	granularity string
+	period      time.Duration
+	last        time.Time
+}
+
+func NewBarScheduler(c *Client, instrument, granularity string) (*BarScheduler, error) {
+	period, ok := granularityDurations[granularity]
+	if !ok {
+		return nil, fmt.Errorf("bar scheduling does not support granularity %q", granularity)
+	}
+	return &BarScheduler{client: c, instrument: instrument, granularity: granularity, period: period}, nil
+}
+
+// Resume makes Run start after the bar that opened at lastBar, delivering
+// every bar missed since then before waiting for new ones. Without it Run
+// starts with the next bar to close.
+func (s *BarScheduler) Resume(lastBar time.Time) {
+	s.last = lastBar
+}
+
+// Run delivers each closed candle to fn in order until ctx is cancelled. fn
+// runs on Run's goroutine, so a slow fn delays later bars but never skips
+// them. Fetch errors are logged and retried.
+func (s *BarScheduler) Run(ctx context.Context, fn func(Candle)) error {
+	clock := s.client.clock
+	if s.last.IsZero() {
+		for {
+			latest, err := s.client.getCandles(s.instrument, CandleOptions{Granularity: s.granularity, Count: 2})
+			if err == nil && len(latest.Candles) > 0 {
+				s.last = latest.Candles[len(latest.Candles)-1].Time
+				break
+			}
+			if err != nil {
+				log.Printf("Error fetching latest %s %s bar: %v", s.instrument, s.granularity, err)
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-clock.After(s.retryDelay()):
+			}
+		}
+	}
+
+	for {
+		// The bar after last opens one period later and closes one period
+		// after that.
+		wait := s.last.Add(2 * s.period).Add(barSettleDelay).Sub(clock.Now())
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-clock.After(wait):
+		}
+
+		if delivered := s.deliver(ctx, fn); delivered {
+			continue
+		}
+
+		// Nothing new yet, e.g. across a weekend or when OANDA is late.
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-clock.After(s.retryDelay()):
+		}
+	}
+}
+
+// deliver fetches and passes on every complete bar after last, reporting
+// whether there were any.
+func (s *BarScheduler) deliver(ctx context.Context, fn func(Candle)) bool {
+	candlesResponse, err := s.client.getCandles(s.instrument, CandleOptions{
+		Granularity: s.granularity,
+		From:        s.last,
+		Count:       maxCandleCount,
+	})
+	if err != nil {
+		log.Printf("Error fetching %s %s bars: %v", s.instrument, s.granularity, err)
+		return false
+	}
+
+	delivered := false
+	for _, candle := range candlesResponse.Candles {
+		if !candle.Time.After(s.last) {
+			continue
+		}
+		if ctx.Err() != nil {
+			return delivered
+		}
+		fn(candle)
+		s.last = candle.Time
+		delivered = true
+	}
+	return delivered
+}
+
+func (s *BarScheduler) retryDelay() time.Duration {
+	return min(s.period, time.Minute)
+}
@@ -0,0 +1,141 @@
+package trader
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const basketPollInterval = time.Second
+
+// BasketLeg is the outcome of one order of a basket. Filled legs have a
+// Response with a fill; TradeID is set when the fill opened a trade rather
+// than reducing an existing position.
+type BasketLeg struct {
+	Order    MarketOrder
+	Response *OrderResponse
+	Filled   bool
+	TradeID  string
+	Err      error
+}
+
+type BasketResult struct {
+	Legs []BasketLeg
+}
+
+// Failed returns the legs that didn't fill.
+func (r *BasketResult) Failed() []BasketLeg {
+	var failed []BasketLeg
+	for _, leg := range r.Legs {
+		if !leg.Filled {
+			failed = append(failed, leg)
+		}
+	}
+	return failed
+}
+
+// PlaceBasketAndWait submits every order at once and waits up to timeout for
+// all of them to fill. An order OANDA accepted without filling straight away
+// is polled until it fills, is cancelled or the timeout passes. Legs appear
+// in the order given. If any leg failed, the error lists them and the
+// filled legs are left open; pass the result to UnwindBasket to reverse
+// them.
+func (c *Client) PlaceBasketAndWait(orders []MarketOrder, timeout time.Duration) (*BasketResult, error) {
+	result := BasketResult{Legs: make([]BasketLeg, len(orders))}
+	deadline := c.clock.Now().Add(timeout)
+
+	var wg sync.WaitGroup
+	for i, order := range orders {
+		result.Legs[i].Order = order
+		wg.Add(1)
+		go func(leg *BasketLeg) {
+			defer wg.Done()
+			c.placeBasketLeg(leg, deadline)
+		}(&result.Legs[i])
+	}
+	wg.Wait()
+
+	var failures []string
+	for i, leg := range result.Legs {
+		if !leg.Filled {
+			failures = append(failures, fmt.Sprintf("leg %d %s %s: %v", i, leg.Order.Units, leg.Order.Instrument, leg.Err))
+		}
+	}
+	if len(failures) > 0 {
+		return &result, fmt.Errorf("%d of %d basket orders did not fill: %s", len(failures), len(orders), strings.Join(failures, "; "))
+	}
+	return &result, nil
+}
+
+func (c *Client) placeBasketLeg(leg *BasketLeg, deadline time.Time) {
+	units, err := strconv.Atoi(leg.Order.Units)
+	if err != nil {
+		leg.Err = fmt.Errorf("invalid units %q: %w", leg.Order.Units, err)
+		return
+	}
+
+	leg.Response, leg.Err = c.submitMarketOrder(leg.Order, units)
+	if leg.Err != nil {
+		return
+	}
+	if fill := leg.Response.OrderFillTransaction; fill.ID != "" {
+		leg.Filled, leg.TradeID = true, fill.TradeOpened.TradeID
+		return
+	}
+	if cancel := leg.Response.OrderCancelTransaction; cancel != nil {
+		leg.Err = fmt.Errorf("cancelled: %s", cancel.Reason)
+		return
+	}
+
+	orderID := leg.Response.OrderCreateTransaction.ID
+	for {
+		order, err := c.getOrder(orderID)
+		if err != nil {
+			leg.Err = err
+			return
+		}
+		switch order.State {
+		case "FILLED":
+			leg.Filled, leg.TradeID = true, order.TradeOpenedID
+			return
+		case "CANCELLED":
+			leg.Err = fmt.Errorf("order %s was cancelled", orderID)
+			return
+		}
+
+		wait := deadline.Sub(c.clock.Now())
+		if wait <= 0 {
+			leg.Err = fmt.Errorf("order %s still %s at the timeout", orderID, strings.ToLower(order.State))
+			return
+		}
+		<-c.clock.After(min(wait, basketPollInterval))
+	}
+}
+
+// UnwindBasket reverses every filled leg of result, closing the trades they
+// opened and restoring the units they closed or reduced.
+func (c *Client) UnwindBasket(result *BasketResult) error {
+	var errs []error
+	for i, leg := range result.Legs {
+		if !leg.Filled || leg.Response == nil {
+			continue
+		}
+		if leg.Response.OrderFillTransaction.ID == "" {
+			if leg.TradeID == "" {
+				errs = append(errs, fmt.Errorf("leg %d %s filled later without opening a trade and must be unwound by hand", i, leg.Order.Instrument))
+				continue
+			}
+			if _, err := c.closeTrade(leg.TradeID, "ALL", CloseTradeOptions{}); err != nil {
+				errs = append(errs, fmt.Errorf("leg %d %s: %w", i, leg.Order.Instrument, err))
+			}
+			continue
+		}
+		if _, err := c.unwindFill(leg.Response); err != nil {
+			errs = append(errs, fmt.Errorf("leg %d %s: %w", i, leg.Order.Instrument, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,109 @@
+package trader
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// BracketResult identifies the trade opened by PlaceBracket and the stop
+// loss and take profit orders attached to it.
+type BracketResult struct {
+	Entry             *OrderResponse
+	TradeID           string
+	StopLossOrderID   string
+	TakeProfitOrderID string
+}
+
+// BracketIncompleteError is returned by PlaceBracket when the entry filled
+// but its stop loss or take profit wasn't created. The trade is open and
+// needs protecting by hand.
+type BracketIncompleteError struct {
+	TradeID           string
+	MissingStopLoss   bool
+	MissingTakeProfit bool
+	Reasons           []string
+}
+
+func (e *BracketIncompleteError) Error() string {
+	var missing []string
+	if e.MissingStopLoss {
+		missing = append(missing, "stop loss")
+	}
+	if e.MissingTakeProfit {
+		missing = append(missing, "take profit")
+	}
+	msg := fmt.Sprintf("trade %s is open without its %s", e.TradeID, strings.Join(missing, " and "))
+	if len(e.Reasons) > 0 {
+		msg += ": " + strings.Join(e.Reasons, ", ")
+	}
+	return msg
+}
+
+// PlaceBracket enters a market order with a stop loss and take profit
+// created on fill, then looks up the order's related transactions to
+// confirm all three exist. If the entry didn't fill nothing is open and an
+// error is returned. If it filled but either protective order is missing,
+// the result is returned together with a *BracketIncompleteError.
+func (c *Client) PlaceBracket(instrument string, units int, stopPrice, targetPrice float32) (*BracketResult, error) {
+	if units == 0 {
+		return nil, fmt.Errorf("bracket units must be non-zero")
+	}
+	if (units > 0 && stopPrice >= targetPrice) || (units < 0 && stopPrice <= targetPrice) {
+		return nil, fmt.Errorf("stop %g and target %g are on the wrong sides for %d units", stopPrice, targetPrice, units)
+	}
+
+	stop := c.formatPrice(instrument, float64(stopPrice))
+	target := c.formatPrice(instrument, float64(targetPrice))
+
+	entry, err := c.submitMarketOrder(MarketOrder{
+		Units:            strconv.Itoa(units),
+		Instrument:       instrument,
+		TimeInForce:      "FOK",
+		Type:             "MARKET",
+		PositionFill:     "DEFAULT",
+		StopLossOnFill:   &OnFillDetails{Price: stop, TimeInForce: "GTC"},
+		TakeProfitOnFill: &OnFillDetails{Price: target, TimeInForce: "GTC"},
+	}, units)
+	if err != nil {
+		return nil, err
+	}
+
+	result := BracketResult{Entry: entry, TradeID: entry.OrderFillTransaction.TradeOpened.TradeID}
+	if result.TradeID == "" {
+		return &result, fmt.Errorf("bracket entry in %s was not filled", instrument)
+	}
+
+	incomplete := BracketIncompleteError{TradeID: result.TradeID, MissingStopLoss: true, MissingTakeProfit: true}
+	if ids := entry.RelatedTransactionIDs; len(ids) > 0 {
+		related, err := c.getTransactionRange(ids[0], ids[len(ids)-1])
+		if err != nil {
+			incomplete.Reasons = append(incomplete.Reasons, fmt.Sprintf("related transactions could not be fetched: %v", err))
+			return &result, &incomplete
+		}
+
+		for _, txn := range related {
+			var order ProtectiveOrderTransaction
+			if err := txn.Decode(&order); err != nil || order.TradeID != result.TradeID {
+				continue
+			}
+			switch txn.Type {
+			case "STOP_LOSS_ORDER":
+				result.StopLossOrderID, incomplete.MissingStopLoss = txn.ID, false
+			case "TAKE_PROFIT_ORDER":
+				result.TakeProfitOrderID, incomplete.MissingTakeProfit = txn.ID, false
+			case "STOP_LOSS_ORDER_REJECT", "TAKE_PROFIT_ORDER_REJECT":
+				var reject struct {
+					RejectReason string `json:"rejectReason"`
+				}
+				txn.Decode(&reject)
+				incomplete.Reasons = append(incomplete.Reasons, txn.Type+" "+reject.RejectReason)
+			}
+		}
+	}
+
+	if incomplete.MissingStopLoss || incomplete.MissingTakeProfit {
+		return &result, &incomplete
+	}
+	return &result, nil
+}
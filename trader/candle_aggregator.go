@@ -0,0 +1,151 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// CandleAggregator builds candles for one instrument from the pricing
+// stream, delivering each bar as soon as the local clock passes its end
+// instead of waiting for OANDA to publish it. Bars are aligned to UTC wall
+// clock time, so daily bars start at midnight UTC rather than OANDA's 17:00
+// New York, and are built from mid prices with Volume counting the ticks.
+//
+// A period without any prices produces no candle unless FillGaps is set, in
+// which case a flat candle at the previous close with zero volume is
+// delivered. Set FillGaps before calling Run.
+type CandleAggregator struct {
+	client     *Client
+	instrument string
+	period     time.Duration
+	FillGaps   bool
+
+	mu      sync.Mutex
+	current *Candle
+}
+
+func NewCandleAggregator(c *Client, instrument, granularity string) (*CandleAggregator, error) {
+	period, ok := granularityDurations[granularity]
+	if !ok {
+		return nil, fmt.Errorf("candle aggregation does not support granularity %q", granularity)
+	}
+	return &CandleAggregator{client: c, instrument: instrument, period: period}, nil
+}
+
+// Current returns the bar still being built, or false before the first
+// price or, without FillGaps, while a period is quiet.
+func (a *CandleAggregator) Current() (Candle, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.current == nil {
+		return Candle{}, false
+	}
+	candle := *a.current
+	mid := *candle.Mid
+	candle.Mid = &mid
+	return candle, true
+}
+
+// Run streams prices for the instrument and delivers each completed candle
+// to fn, in order, until ctx is cancelled or the stream ends. fn runs on
+// Run's goroutine. A price that arrives after its bar was delivered is
+// dropped.
+func (a *CandleAggregator) Run(ctx context.Context, fn func(Candle)) error {
+	prices, err := a.client.StreamPrices(ctx, []string{a.instrument})
+	if err != nil {
+		return err
+	}
+
+	clock := a.client.clock
+	for {
+		var boundary <-chan time.Time
+		if end, ok := a.currentEnd(); ok {
+			boundary = clock.After(end.Sub(clock.Now()))
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-boundary:
+			for _, candle := range a.closeUntil(clock.Now()) {
+				fn(candle)
+			}
+		case price, ok := <-prices:
+			if !ok {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				return fmt.Errorf("%s pricing stream closed", a.instrument)
+			}
+			for _, candle := range a.add(price) {
+				fn(candle)
+			}
+		}
+	}
+}
+
+func (a *CandleAggregator) currentEnd() (time.Time, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.current == nil {
+		return time.Time{}, false
+	}
+	return a.current.Time.Add(a.period), true
+}
+
+// add folds price into the current bar, first closing every bar that ended
+// before it.
+func (a *CandleAggregator) add(price Price) []Candle {
+	if price.Instrument != a.instrument {
+		return nil
+	}
+	start := price.Time.Truncate(a.period)
+	completed := a.closeUntil(start)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	mid := (price.Bid + price.Ask) / 2
+	switch {
+	case a.current == nil:
+		a.current = &Candle{Time: start, Volume: 1, Mid: &OHLC{Open: mid, High: mid, Low: mid, Close: mid}}
+	case start.Before(a.current.Time):
+		// Late for a bar already delivered.
+	case a.current.Volume == 0:
+		// The first tick of a bar FillGaps started flat replaces it.
+		a.current.Volume = 1
+		a.current.Mid = &OHLC{Open: mid, High: mid, Low: mid, Close: mid}
+	default:
+		ohlc := a.current.Mid
+		ohlc.High = max(ohlc.High, mid)
+		ohlc.Low = min(ohlc.Low, mid)
+		ohlc.Close = mid
+		a.current.Volume++
+	}
+	return completed
+}
+
+// closeUntil completes every bar that ends at or before t, filling quiet
+// periods with flat bars when FillGaps is set.
+func (a *CandleAggregator) closeUntil(t time.Time) []Candle {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var completed []Candle
+	for a.current != nil && !a.current.Time.Add(a.period).After(t) {
+		candle := *a.current
+		candle.Complete = true
+		completed = append(completed, candle)
+
+		a.current = nil
+		if a.FillGaps {
+			flat := candle.Mid.Close
+			a.current = &Candle{Time: candle.Time.Add(a.period), Mid: &OHLC{Open: flat, High: flat, Low: flat, Close: flat}}
+		}
+	}
+	return completed
+}
@@ -0,0 +1,120 @@
+package trader
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// candleCache keeps getCandles responses on disk, one JSON file per request
+// named after a hash of the instrument and query.
+type candleCache struct {
+	dir    string
+	maxAge time.Duration
+}
+
+// WithCandleCache serves repeated candle requests from files in dir, which
+// is created if needed, instead of asking OANDA again. A cached response is
+// used until it is maxAge old, or indefinitely if maxAge is zero, which
+// suits fixed historical ranges; latest-N requests should use a short
+// maxAge. Requests that include the incomplete current candle are never
+// cached.
+func WithCandleCache(dir string, maxAge time.Duration) Option {
+	return func(c *Client) {
+		c.candleCache = &candleCache{dir: dir, maxAge: maxAge}
+	}
+}
+
+func (cc *candleCache) path(instrument string, q url.Values) string {
+	sum := sha256.Sum256([]byte(instrument + "?" + q.Encode()))
+	return filepath.Join(cc.dir, instrument+"-"+hex.EncodeToString(sum[:8])+".json")
+}
+
+func (cc *candleCache) load(path string, now time.Time) (*CandlesResponse, bool) {
+	info, err := os.Stat(path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			log.Printf("Error reading candle cache: %v", err)
+		}
+		return nil, false
+	}
+	if cc.maxAge > 0 && now.Sub(info.ModTime()) > cc.maxAge {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.Printf("Error reading candle cache: %v", err)
+		return nil, false
+	}
+	var response CandlesResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		log.Printf("Error decoding cached candles in %s: %v", path, err)
+		return nil, false
+	}
+	return &response, true
+}
+
+func (cc *candleCache) store(path string, response *CandlesResponse) error {
+	data, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(cc.dir, 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(cc.dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// ClearCandleCache deletes the cached candles of instrument, or of every
+// instrument if it is empty. It does nothing without WithCandleCache.
+func (c *Client) ClearCandleCache(instrument string) error {
+	if c.candleCache == nil {
+		return nil
+	}
+
+	entries, err := os.ReadDir(c.candleCache.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	prefix := ""
+	if instrument != "" {
+		prefix = instrument + "-"
+	}
+	var errs []error
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		if err := os.Remove(filepath.Join(c.candleCache.dir, name)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,269 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	candlesEndpoint = "/v3/instruments/{instrument}/candles"
+	maxCandleCount  = 5000
+)
+
+var weekdays = map[string]bool{
+	"Monday": true, "Tuesday": true, "Wednesday": true, "Thursday": true,
+	"Friday": true, "Saturday": true, "Sunday": true,
+}
+
+// CandleOptions holds the optional query parameters for getCandles. Zero
+// values are left out of the request so OANDA's defaults apply.
+//
+// Count may be combined with From to fetch that many candles since a time,
+// or with To for the candles leading up to it, but not with both.
+type CandleOptions struct {
+	Granularity string
+	Count       int
+	From        time.Time
+	To          time.Time
+
+	// Smooth builds each candle's open from the previous candle's close
+	// rather than the first price in its own period.
+	Smooth bool
+
+	// AlignmentTimezone, DailyAlignment and WeeklyAlignment shift the
+	// boundaries of daily and weekly candles. DailyAlignment is an hour
+	// (0-23) in AlignmentTimezone; leave it nil for OANDA's default of 17.
+	AlignmentTimezone string
+	DailyAlignment    *int
+	WeeklyAlignment   string
+
+	// Price selects the candle components to fetch, any combination of
+	// "M" (mid), "B" (bid) and "A" (ask). It defaults to "M".
+	Price string
+
+	// IncludeIncomplete keeps the still-forming current candle. By default
+	// it is dropped, so the last candle of a response may be missing.
+	IncludeIncomplete bool
+}
+
+type RawCandlesResponse struct {
+	Instrument  string `json:"instrument"`
+	Granularity string `json:"granularity"`
+	Candles     []struct {
+		Complete bool     `json:"complete"`
+		Time     string   `json:"time"`
+		Volume   int      `json:"volume"`
+		Mid      *RawOHLC `json:"mid"`
+		Bid      *RawOHLC `json:"bid"`
+		Ask      *RawOHLC `json:"ask"`
+	} `json:"candles"`
+}
+
+type RawOHLC struct {
+	O float32 `json:"o,string"`
+	H float32 `json:"h,string"`
+	L float32 `json:"l,string"`
+	C float32 `json:"c,string"`
+}
+
+type CandlesResponse struct {
+	Instrument  string   `json:"instrument"`
+	Granularity string   `json:"granularity"`
+	Candles     []Candle `json:"candles"`
+}
+
+type Candle struct {
+	Time     time.Time
+	Volume   int
+	Complete bool
+	Mid      *OHLC
+	Bid      *OHLC
+	Ask      *OHLC
+}
+
+type OHLC struct {
+	Open  float32
+	High  float32
+	Low   float32
+	Close float32
+}
+
+func (opts *CandleOptions) validate() error {
+	if opts.Count < 0 || opts.Count > maxCandleCount {
+		return fmt.Errorf("candle count must be between 1 and %d, got %d", maxCandleCount, opts.Count)
+	}
+	if opts.Count > 0 && !opts.From.IsZero() && !opts.To.IsZero() {
+		return fmt.Errorf("candle count cannot be combined with both from and to")
+	}
+	if !opts.From.IsZero() && !opts.To.IsZero() && !opts.From.Before(opts.To) {
+		return fmt.Errorf("candle from time %s is not before to time %s", opts.From, opts.To)
+	}
+	if opts.AlignmentTimezone != "" {
+		if _, err := time.LoadLocation(opts.AlignmentTimezone); err != nil {
+			return fmt.Errorf("invalid alignment timezone %q: %w", opts.AlignmentTimezone, err)
+		}
+	}
+	if opts.DailyAlignment != nil && (*opts.DailyAlignment < 0 || *opts.DailyAlignment > 23) {
+		return fmt.Errorf("daily alignment must be an hour between 0 and 23, got %d", *opts.DailyAlignment)
+	}
+	if opts.WeeklyAlignment != "" && !weekdays[opts.WeeklyAlignment] {
+		return fmt.Errorf("invalid weekly alignment %q, expected a day such as Friday", opts.WeeklyAlignment)
+	}
+	for i, component := range opts.Price {
+		if !strings.ContainsRune("MBA", component) || strings.ContainsRune(opts.Price[:i], component) {
+			return fmt.Errorf("invalid price components %q, expected a combination of M, B and A", opts.Price)
+		}
+	}
+	return nil
+}
+
+func (opts *CandleOptions) query(q url.Values) {
+	if opts.Granularity != "" {
+		q.Add("granularity", opts.Granularity)
+	}
+	if opts.Price != "" {
+		q.Add("price", opts.Price)
+	}
+	if opts.Count > 0 {
+		q.Add("count", strconv.Itoa(opts.Count))
+	}
+	if !opts.From.IsZero() {
+		q.Add("from", opts.From.UTC().Format(time.RFC3339))
+	}
+	if !opts.To.IsZero() {
+		q.Add("to", opts.To.UTC().Format(time.RFC3339))
+	}
+	if opts.Smooth {
+		q.Add("smooth", "true")
+	}
+	if opts.AlignmentTimezone != "" {
+		q.Add("alignmentTimezone", opts.AlignmentTimezone)
+	}
+	if opts.DailyAlignment != nil {
+		q.Add("dailyAlignment", strconv.Itoa(*opts.DailyAlignment))
+	}
+	if opts.WeeklyAlignment != "" {
+		q.Add("weeklyAlignment", opts.WeeklyAlignment)
+	}
+}
+
+func parseRawCandles(rawResponse *RawCandlesResponse, price string, includeIncomplete bool) (*CandlesResponse, error) {
+	if price == "" {
+		price = "M"
+	}
+
+	response := CandlesResponse{
+		Instrument:  rawResponse.Instrument,
+		Granularity: rawResponse.Granularity,
+		Candles:     make([]Candle, 0, len(rawResponse.Candles)),
+	}
+
+	for _, rawCandle := range rawResponse.Candles {
+		if !rawCandle.Complete && !includeIncomplete {
+			continue
+		}
+
+		t, err := time.Parse(time.RFC3339Nano, rawCandle.Time)
+		if err != nil {
+			return nil, fmt.Errorf("invalid candle time %q: %w", rawCandle.Time, err)
+		}
+
+		candle := Candle{
+			Time:     t,
+			Volume:   rawCandle.Volume,
+			Complete: rawCandle.Complete,
+		}
+		if strings.Contains(price, "M") {
+			if rawCandle.Mid == nil {
+				return nil, fmt.Errorf("No mid prices recieved for candle at %s.", rawCandle.Time)
+			}
+			candle.Mid = rawCandle.Mid.toOHLC()
+		}
+		if strings.Contains(price, "B") {
+			if rawCandle.Bid == nil {
+				return nil, fmt.Errorf("No bid prices recieved for candle at %s.", rawCandle.Time)
+			}
+			candle.Bid = rawCandle.Bid.toOHLC()
+		}
+		if strings.Contains(price, "A") {
+			if rawCandle.Ask == nil {
+				return nil, fmt.Errorf("No ask prices recieved for candle at %s.", rawCandle.Time)
+			}
+			candle.Ask = rawCandle.Ask.toOHLC()
+		}
+
+		response.Candles = append(response.Candles, candle)
+	}
+
+	return &response, nil
+}
+
+func (raw *RawOHLC) toOHLC() *OHLC {
+	return &OHLC{Open: raw.O, High: raw.H, Low: raw.L, Close: raw.C}
+}
+
+func (c *Client) getCandles(instrument string, opts CandleOptions) (*CandlesResponse, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
+	q := url.Values{}
+	opts.query(q)
+
+	cachePath := ""
+	if c.candleCache != nil && !opts.IncludeIncomplete {
+		cachePath = c.candleCache.path(instrument, q)
+		if response, ok := c.candleCache.load(cachePath, c.clock.Now()); ok {
+			return response, nil
+		}
+	}
+
+	var rawResponse RawCandlesResponse
+	endpoint := strings.Replace(candlesEndpoint, "{instrument}", instrument, 1)
+	err := c.do(context.Background(), "GET", endpoint, q, nil, 200, &rawResponse)
+	if err != nil {
+		return nil, err
+	}
+	response, err := parseRawCandles(&rawResponse, opts.Price, opts.IncludeIncomplete)
+	if err != nil {
+		return nil, err
+	}
+
+	if cachePath != "" {
+		if err := c.candleCache.store(cachePath, response); err != nil {
+			log.Printf("Error caching %s candles: %v", instrument, err)
+		}
+	}
+	return response, nil
+}
+
+// PriceAt returns the mid price of instrument at t, approximated from
+// 5-second candles, the finest OANDA serves. When a candle contains t its
+// close is returned, which may be up to 5 seconds after t. Otherwise, as
+// when t falls outside market hours or in a quiet spell without ticks, the
+// close of the last candle before t is returned.
+func (c *Client) PriceAt(instrument string, t time.Time) (float64, error) {
+	candlesResponse, err := c.getCandles(instrument, CandleOptions{
+		Granularity:       "S5",
+		To:                t.Add(5 * time.Second),
+		Count:             2,
+		IncludeIncomplete: true,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for i := len(candlesResponse.Candles) - 1; i >= 0; i-- {
+		candle := candlesResponse.Candles[i]
+		if candle.Time.After(t) || candle.Mid == nil {
+			continue
+		}
+		return float64(candle.Mid.Close), nil
+	}
+	return 0, fmt.Errorf("no %s prices found at or before %s", instrument, t)
+}
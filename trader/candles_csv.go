@@ -0,0 +1,183 @@
+package trader
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CSVColumns names the header of each column LoadCandlesCSVColumns reads.
+// Headers are matched case-insensitively. Volume may be empty when the file
+// has none.
+type CSVColumns struct {
+	Time, Open, High, Low, Close, Volume string
+}
+
+var DefaultCSVColumns = CSVColumns{
+	Time:   "time",
+	Open:   "open",
+	High:   "high",
+	Low:    "low",
+	Close:  "close",
+	Volume: "volume",
+}
+
+// CSVRowError describes a row LoadCandlesCSV skipped.
+type CSVRowError struct {
+	Line int
+	Err  error
+}
+
+func (e *CSVRowError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *CSVRowError) Unwrap() error {
+	return e.Err
+}
+
+// LoadCandlesCSV is LoadCandlesCSVColumns with DefaultCSVColumns.
+func LoadCandlesCSV(r io.Reader) ([]Candle, error) {
+	return LoadCandlesCSVColumns(r, DefaultCSVColumns)
+}
+
+// LoadCandlesCSVColumns reads complete mid candles from CSV with a header
+// row, for backtesting on history from outside OANDA. Times may be RFC 3339
+// or Unix epoch seconds or milliseconds. Malformed rows are skipped; if any
+// were, the candles that did parse are returned with an error joining a
+// *CSVRowError per skipped row.
+func LoadCandlesCSVColumns(r io.Reader, cols CSVColumns) ([]Candle, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading CSV header: %w", err)
+	}
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	column := func(name string) (int, error) {
+		i, ok := index[strings.ToLower(name)]
+		if !ok {
+			return 0, fmt.Errorf("CSV header has no %q column", name)
+		}
+		return i, nil
+	}
+
+	var fields [5]int
+	for i, name := range []string{cols.Time, cols.Open, cols.High, cols.Low, cols.Close} {
+		if fields[i], err = column(name); err != nil {
+			return nil, err
+		}
+	}
+	volumeField := -1
+	if cols.Volume != "" {
+		if volumeField, err = column(cols.Volume); err != nil {
+			return nil, err
+		}
+	}
+
+	var candles []Candle
+	var rowErrs []error
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line, _ := reader.FieldPos(0)
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				rowErrs = append(rowErrs, &CSVRowError{Line: parseErr.Line, Err: parseErr.Err})
+				continue
+			}
+			return nil, err
+		}
+
+		candle, err := parseCSVCandle(record, fields, volumeField)
+		if err != nil {
+			rowErrs = append(rowErrs, &CSVRowError{Line: line, Err: err})
+			continue
+		}
+		candles = append(candles, *candle)
+	}
+
+	if len(rowErrs) > 0 {
+		return candles, fmt.Errorf("skipped %d malformed CSV rows: %w", len(rowErrs), errors.Join(rowErrs...))
+	}
+	return candles, nil
+}
+
+func parseCSVCandle(record []string, fields [5]int, volumeField int) (*Candle, error) {
+	value := func(i int) (string, error) {
+		if i >= len(record) {
+			return "", fmt.Errorf("row has %d fields, wanted at least %d", len(record), i+1)
+		}
+		return strings.TrimSpace(record[i]), nil
+	}
+
+	rawTime, err := value(fields[0])
+	if err != nil {
+		return nil, err
+	}
+	t, err := parseCSVTime(rawTime)
+	if err != nil {
+		return nil, err
+	}
+
+	var prices [4]float32
+	for i, field := range fields[1:] {
+		raw, err := value(field)
+		if err != nil {
+			return nil, err
+		}
+		price, err := strconv.ParseFloat(raw, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid price %q", raw)
+		}
+		prices[i] = float32(price)
+	}
+
+	candle := Candle{
+		Time:     t,
+		Complete: true,
+		Mid:      &OHLC{Open: prices[0], High: prices[1], Low: prices[2], Close: prices[3]},
+	}
+	if volumeField >= 0 {
+		raw, err := value(volumeField)
+		if err != nil {
+			return nil, err
+		}
+		volume, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid volume %q", raw)
+		}
+		candle.Volume = int(volume)
+	}
+	return &candle, nil
+}
+
+// parseCSVTime accepts RFC 3339 or Unix epoch seconds, with milliseconds
+// assumed for values too large to be seconds.
+func parseCSVTime(raw string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, raw); err == nil {
+		return t, nil
+	}
+
+	epoch, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q", raw)
+	}
+	if epoch > 1e11 {
+		return time.UnixMilli(int64(epoch)).UTC(), nil
+	}
+	sec, frac := int64(epoch), epoch-float64(int64(epoch))
+	return time.Unix(sec, int64(frac*1e9)).UTC(), nil
+}
@@ -0,0 +1,51 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+const accountChangesEndpoint = "/v3/accounts/{accountID}/changes"
+
+type AccountChangesResponse struct {
+	Changes           AccountChanges `json:"changes"`
+	LastTransactionID string         `json:"lastTransactionID"`
+}
+
+// AccountChanges lists what happened on the account after a transaction.
+// Trades carry OANDA's trade summary, so their dependent orders are not
+// filled in.
+type AccountChanges struct {
+	OrdersCreated   []Order       `json:"ordersCreated"`
+	OrdersCancelled []Order       `json:"ordersCancelled"`
+	OrdersFilled    []Order       `json:"ordersFilled"`
+	OrdersTriggered []Order       `json:"ordersTriggered"`
+	TradesOpened    []Trade       `json:"tradesOpened"`
+	TradesReduced   []Trade       `json:"tradesReduced"`
+	TradesClosed    []Trade       `json:"tradesClosed"`
+	Positions       []Position    `json:"positions"`
+	Transactions    []Transaction `json:"transactions"`
+}
+
+// SyncSinceDisconnect returns the account changes after lastTxID, such as
+// fills and closes missed while a stream was down. The Client's last
+// transaction ID moves on to the newest one in the response, so the next
+// sync can start from LastTransactionID.
+func (c *Client) SyncSinceDisconnect(lastTxID string) (*AccountChanges, error) {
+	if lastTxID == "" {
+		return nil, fmt.Errorf("a transaction ID to sync from is required")
+	}
+
+	q := url.Values{}
+	q.Add("sinceTransactionID", lastTxID)
+
+	var changesResponse AccountChangesResponse
+	err := c.do(context.Background(), "GET", accountChangesEndpoint, q, nil, 200, &changesResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	sortTransactions(changesResponse.Changes.Transactions)
+	return &changesResponse.Changes, nil
+}
@@ -0,0 +1,327 @@
+package trader
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const version = "0.1.0"
+
+const defaultUserAgent = "alGotrade/" + version
+
+// defaultMaxResponseSize bounds a REST response body. The largest normal
+// responses, full transaction pages and 5000 candles with bid and ask, are
+// well under it.
+const defaultMaxResponseSize = 16 << 20
+
+var ErrResponseTooLarge = errors.New("response body exceeds the maximum size")
+
+// Client holds the credentials and configuration shared by every request to
+// the OANDA API.
+//
+// A Client is safe for concurrent use by multiple goroutines once NewClient
+// returns. Fields set by options are read-only afterwards; mutable state is
+// either guarded by mu or, for the price cache, order dedup, retry budget
+// and rate limiter, by their own locks. An AuditSink, PeakStore, StateStore,
+// Tracer or callback supplied by the caller must be safe for concurrent use
+// itself.
+type Client struct {
+	creds      *Credentials
+	httpClient Doer
+	auditSink  AuditSink
+	userAgent  string
+	clock      Clock
+
+	maxOrderUnits    int
+	maxPositionUnits map[string]int
+	maxSpreadPips    map[string]float64
+	maxQuoteAge      time.Duration
+
+	skipTradeableCheck bool
+	stateStore         StateStore
+	drawdown           *DrawdownTracker
+
+	maxResponseSize      int64
+	maxRetries           int
+	retryBackoff         time.Duration
+	retryBudget          *retryBudget
+	rateLimiter          *rateLimiter
+	priceCache           priceCache
+	candleCache          *candleCache
+	stalePriceFallback   bool
+	dedup                orderDedup
+	instrumentLocks      instrumentLocks
+	maxConcurrentOrders  int
+	orderSlots           chan struct{}
+	rejectionPolicy      RejectionPolicy
+	watchlists           map[string][]string
+	boundRounding        RoundingMode
+	unboundedInstruments map[string]bool
+	allowedInstruments   map[string]bool
+	deniedInstruments    map[string]bool
+	restrictPricing      bool
+	pricePrecisions      map[string]int
+	tracer               Tracer
+	onReject             func(RejectEvent)
+	preTradeChecks       []func(MarketOrder) error
+	postFillHooks        []func(OrderFillTransaction, string) error
+
+	// mu guards the fields below it.
+	mu           sync.Mutex
+	positionMode PositionMode
+	homeCurrency string
+	instruments  map[string]Instrument
+
+	accountMarginRate float64
+
+	lastTransactionID string
+	dailyBaseline     *DailyBaseline
+	haltedBy          *RejectionError
+
+	watchlistsValidated bool
+}
+
+type Option func(*Client)
+
+// NewClient applies opts, loading credentials and watchlists from
+// config.json unless WithCredentials supplies them.
+func NewClient(opts ...Option) *Client {
+	c := &Client{
+		httpClient: &http.Client{},
+		auditSink:  NopAuditSink{},
+		userAgent:  defaultUserAgent,
+
+		maxResponseSize:     defaultMaxResponseSize,
+		maxConcurrentOrders: defaultMaxConcurrentOrders,
+		clock:               realClock{},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.maxConcurrentOrders > 0 {
+		c.orderSlots = make(chan struct{}, c.maxConcurrentOrders)
+	}
+	if c.creds == nil {
+		cfg := getConfig()
+		c.creds = &cfg.Credentials
+		if c.watchlists == nil {
+			c.watchlists = cfg.Watchlists
+		}
+	}
+	if c.stateStore != nil {
+		c.restoreState()
+	}
+	return c
+}
+
+// WithCredentials uses creds instead of reading config.json.
+func WithCredentials(creds Credentials) Option {
+	return func(c *Client) {
+		c.creds = &creds
+	}
+}
+
+func WithAuditSink(sink AuditSink) Option {
+	return func(c *Client) {
+		c.auditSink = sink
+	}
+}
+
+// WithUserAgent replaces the default "alGotrade/<version>" User-Agent sent
+// with every request, streams included.
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithConnectionPool tunes how many idle keep-alive connections to OANDA
+// are kept open and for how long, so repeated requests reuse an existing
+// TLS connection instead of dialling a new one. Go's default keeps only two
+// idle connections per host. A bot polling every second with a handful of
+// concurrent requests does well with 8 and 90 seconds; keep the timeout
+// above the polling interval or connections expire between polls.
+func WithConnectionPool(maxIdleConnsPerHost int, idleConnTimeout time.Duration) Option {
+	return func(c *Client) {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.MaxIdleConns = max(transport.MaxIdleConns, maxIdleConnsPerHost)
+		transport.MaxIdleConnsPerHost = maxIdleConnsPerHost
+		transport.IdleConnTimeout = idleConnTimeout
+		if httpClient, ok := c.httpClient.(*http.Client); ok {
+			httpClient.Transport = transport
+		}
+	}
+}
+
+func (c *Client) setHeaders(req *http.Request) {
+	req.Header.Set("Authorization", "Bearer "+c.creds.BearerToken)
+	req.Header.Set("User-Agent", c.userAgent)
+}
+
+func (c *Client) accountEndpoint(endpoint string) string {
+	return strings.Replace(endpoint, "{accountID}", c.creds.AccountID, 1)
+}
+
+// WithMaxResponseSize caps the size of a REST response body at n bytes, 16
+// MB by default. Larger responses fail with ErrResponseTooLarge rather than
+// being read into memory. Stream messages are capped at 1 MB, or n if that
+// is smaller.
+func WithMaxResponseSize(n int64) Option {
+	return func(c *Client) {
+		c.maxResponseSize = n
+	}
+}
+
+// readBody reads all of r, failing once more than limit bytes arrive.
+func readBody(r io.Reader, limit int64) ([]byte, error) {
+	body, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > limit {
+		return nil, fmt.Errorf("%w of %d bytes", ErrResponseTooLarge, limit)
+	}
+	return body, nil
+}
+
+// APIError is returned for a response with an unexpected status code.
+type APIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("unexpected status code: %d, body: %s", e.StatusCode, e.Body)
+}
+
+// WithRetry retries GET requests up to maxRetries times after a transient
+// failure, waiting backoff before the first retry and doubling it for each
+// one after. Orders and other writes are never retried. There are no
+// retries by default.
+func WithRetry(maxRetries int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.retryBackoff = backoff
+	}
+}
+
+// isTransient reports whether err is worth retrying: network failures,
+// rate limiting and server errors, but not cancellation or client errors.
+func isTransient(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= 500
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// do sends an authenticated request to endpoint, encoding payload as the JSON
+// body when it is non-nil, and decodes the response into out. Any status other
+// than wantStatus is returned as an *APIError.
+func (c *Client) do(ctx context.Context, method, endpoint string, query url.Values, payload any, wantStatus int, out any) (err error) {
+	ctx, endSpan := c.startSpan(ctx, method, endpoint, payload)
+	defer func() {
+		status := 0
+		if err == nil {
+			status = wantStatus
+		}
+		endSpan(status, err)
+	}()
+
+	var jsonBody []byte
+	if payload != nil {
+		var err error
+		jsonBody, err = json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+	}
+
+	body, err := c.doOnce(ctx, method, endpoint, query, jsonBody, wantStatus)
+	backoff := c.retryBackoff
+	for attempt := 0; err != nil && method == "GET" && attempt < c.maxRetries && isTransient(err); attempt++ {
+		if c.retryBudget != nil && !c.retryBudget.take(c.clock.Now()) {
+			err = fmt.Errorf("%w: %w", ErrRetryBudgetExhausted, err)
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-c.clock.After(backoff):
+		}
+		backoff *= 2
+		body, err = c.doOnce(ctx, method, endpoint, query, jsonBody, wantStatus)
+	}
+	if err != nil {
+		c.reportReject(method, endpoint, payload, jsonBody, err)
+		return err
+	}
+
+	var header struct {
+		LastTransactionID string `json:"lastTransactionID"`
+	}
+	if json.Unmarshal(body, &header) == nil && header.LastTransactionID != "" {
+		c.observeTransactionID(header.LastTransactionID)
+	}
+
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(body, out)
+}
+
+func (c *Client) doOnce(ctx context.Context, method, endpoint string, query url.Values, jsonBody []byte, wantStatus int) ([]byte, error) {
+	if c.rateLimiter != nil {
+		if err := c.rateLimiter.wait(ctx, c.clock); err != nil {
+			return nil, err
+		}
+	}
+
+	var reqBody io.Reader
+	if jsonBody != nil {
+		reqBody = bytes.NewReader(jsonBody)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, baseURL+c.accountEndpoint(endpoint), reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	c.setHeaders(req)
+	if jsonBody != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if query != nil {
+		req.URL.RawQuery = query.Encode()
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := readBody(resp.Body, c.maxResponseSize)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != wantStatus {
+		return nil, &APIError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+	return body, nil
+}
@@ -0,0 +1,165 @@
+package trader
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeDoer answers the pricing, instruments and orders endpoints with
+// canned responses.
+type fakeDoer struct {
+	orders atomic.Int64
+}
+
+func (d *fakeDoer) Do(req *http.Request) (*http.Response, error) {
+	path := req.URL.Path
+	switch {
+	case strings.HasSuffix(path, "/pricing"):
+		now := time.Now().UTC().Format(time.RFC3339Nano)
+		return jsonResponse(200, fmt.Sprintf(`{"prices": [{"instrument": "EUR_USD", "time": %q, "tradeable": true,
+			"bids": [{"price": "1.10000"}], "asks": [{"price": "1.10010"}]}]}`, now)), nil
+	case strings.HasSuffix(path, "/instruments"):
+		return jsonResponse(200, `{"instruments": [{"name": "EUR_USD", "pipLocation": -4, "displayPrecision": 5,
+			"tradeUnitsPrecision": 0, "minimumTradeSize": "1", "marginRate": "0.02"}]}`), nil
+	case strings.HasSuffix(path, "/orders") && req.Method == "POST":
+		n := d.orders.Add(1)
+		return jsonResponse(201, fmt.Sprintf(`{"orderCreateTransaction": {"id": "%d", "instrument": "EUR_USD"},
+			"orderFillTransaction": {"id": "%d", "instrument": "EUR_USD", "units": "10", "price": "1.10010",
+			"tradeOpened": {"tradeID": "%d", "units": "10"}}, "lastTransactionID": "%d"}`, 2*n, 2*n+1, 2*n+1, 2*n+1)), nil
+	}
+	return jsonResponse(404, `{"errorMessage": "not found"}`), nil
+}
+
+func jsonResponse(status int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+func newTestClient(doer Doer, opts ...Option) *Client {
+	opts = append([]Option{
+		WithCredentials(Credentials{AccountID: "001-001-1-001", BearerToken: "token"}),
+		WithDoer(doer),
+	}, opts...)
+	return NewClient(opts...)
+}
+
+// TestClientConcurrentUse is meant to be run with -race.
+func TestClientConcurrentUse(t *testing.T) {
+	doer := &fakeDoer{}
+	c := newTestClient(doer, WithPriceCache(time.Millisecond), WithMaxSpreadPips(map[string]float64{"EUR_USD": 5}))
+
+	const workers = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, 2*workers)
+	for range workers {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for range 10 {
+				if _, err := c.getPrices([]string{"EUR_USD"}, PricingOptions{}); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for range 5 {
+				response, err := c.placeMarketOrder(10, "EUR_USD", 1.1002)
+				if err != nil {
+					errs <- err
+					return
+				}
+				if response.OrderFillTransaction.ID == "" {
+					errs <- fmt.Errorf("order was not filled")
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+	if got := doer.orders.Load(); got != workers*5 {
+		t.Errorf("sent %d orders, want %d", got, workers*5)
+	}
+	if c.LastTransactionID() == "" {
+		t.Error("last transaction ID was not recorded")
+	}
+}
+
+// rewriteTransport sends every request to a local test server instead of
+// OANDA, through the Client's own transport.
+type rewriteTransport struct {
+	base *http.Transport
+	host string
+}
+
+func (t *rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = "http"
+	req.URL.Host = t.host
+	return t.base.RoundTrip(req)
+}
+
+func TestConnectionPoolReusesConnections(t *testing.T) {
+	var newConns atomic.Int64
+	fake := &fakeDoer{}
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(5 * time.Millisecond)
+		resp, _ := fake.Do(r)
+		w.WriteHeader(resp.StatusCode)
+		io.Copy(w, resp.Body)
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			newConns.Add(1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	const parallel = 8
+	c := NewClient(
+		WithCredentials(Credentials{AccountID: "001-001-1-001", BearerToken: "token"}),
+		WithConnectionPool(parallel, time.Minute),
+	)
+	httpClient := c.httpClient.(*http.Client)
+	httpClient.Transport = &rewriteTransport{
+		base: httpClient.Transport.(*http.Transport),
+		host: strings.TrimPrefix(server.URL, "http://"),
+	}
+
+	const waves = 5
+	for range waves {
+		var wg sync.WaitGroup
+		for range parallel {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := c.getPrices([]string{"EUR_USD"}, PricingOptions{}); err != nil {
+					t.Error(err)
+				}
+			}()
+		}
+		wg.Wait()
+	}
+
+	if got := newConns.Load(); got > parallel {
+		t.Errorf("opened %d connections for %d requests, want at most %d", got, waves*parallel, parallel)
+	}
+}
@@ -0,0 +1,86 @@
+package trader
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is the Client's source of wall-clock time. Tests can swap in a
+// FakeClock with WithClock to drive expiry, polling and scheduling logic
+// without waiting.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+func WithClock(clock Clock) Option {
+	return func(c *Client) {
+		c.clock = clock
+	}
+}
+
+// FakeClock is a Clock that only moves when Advance or Set is called.
+type FakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []fakeWaiter
+}
+
+type fakeWaiter struct {
+	deadline time.Time
+	ch       chan time.Time
+}
+
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+func (f *FakeClock) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+func (f *FakeClock) After(d time.Duration) <-chan time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- f.now
+		return ch
+	}
+	f.waiters = append(f.waiters, fakeWaiter{deadline: f.now.Add(d), ch: ch})
+	return ch
+}
+
+// Advance moves the clock forward by d and fires every After channel whose
+// deadline has been reached.
+func (f *FakeClock) Advance(d time.Duration) {
+	f.mu.Lock()
+	t := f.now.Add(d)
+	f.mu.Unlock()
+	f.Set(t)
+}
+
+func (f *FakeClock) Set(t time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.now = t
+	pending := f.waiters[:0]
+	for _, waiter := range f.waiters {
+		if waiter.deadline.After(t) {
+			pending = append(pending, waiter)
+			continue
+		}
+		waiter.ch <- t
+	}
+	f.waiters = pending
+}
@@ -0,0 +1,63 @@
+package trader
+
+import (
+	"context"
+	"sort"
+)
+
+// Conflict is an instrument with long and short trades open at once, which
+// on a hedging account usually means a bug rather than a hedge. Closing the
+// trades on SmallerSide leaves the position at NetUnits.
+type Conflict struct {
+	Instrument  string
+	LongTrades  []Trade
+	ShortTrades []Trade
+	LongUnits   float64
+	ShortUnits  float64
+	NetUnits    float64
+	SmallerSide Side
+}
+
+// FindConflictingTrades reports every instrument holding opposing open
+// trades, in instrument order. Netting accounts can't hold both sides, so
+// it only ever finds conflicts on hedging accounts.
+func (c *Client) FindConflictingTrades(ctx context.Context) ([]Conflict, error) {
+	trades, err := c.getOpenTrades()
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	byInstrument := make(map[string]*Conflict)
+	for _, trade := range trades {
+		conflict, ok := byInstrument[trade.Instrument]
+		if !ok {
+			conflict = &Conflict{Instrument: trade.Instrument}
+			byInstrument[trade.Instrument] = conflict
+		}
+		if trade.CurrentUnits > 0 {
+			conflict.LongTrades = append(conflict.LongTrades, trade)
+			conflict.LongUnits += trade.CurrentUnits
+		} else {
+			conflict.ShortTrades = append(conflict.ShortTrades, trade)
+			conflict.ShortUnits += trade.CurrentUnits
+		}
+	}
+
+	var conflicts []Conflict
+	for _, conflict := range byInstrument {
+		if len(conflict.LongTrades) == 0 || len(conflict.ShortTrades) == 0 {
+			continue
+		}
+		conflict.NetUnits = conflict.LongUnits + conflict.ShortUnits
+		conflict.SmallerSide = SideLong
+		if conflict.NetUnits > 0 {
+			conflict.SmallerSide = SideShort
+		}
+		conflicts = append(conflicts, *conflict)
+	}
+	sort.Slice(conflicts, func(i, j int) bool { return conflicts[i].Instrument < conflicts[j].Instrument })
+	return conflicts, nil
+}
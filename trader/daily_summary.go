@@ -0,0 +1,110 @@
+package trader
+
+import (
+	"strconv"
+	"time"
+)
+
+// tradingDayEndHour is the hour in New York at which OANDA's trading day,
+// and its daily candles and financing, roll over.
+const tradingDayEndHour = 17
+
+// DailyPerformance summarises one trading day. Trades counts closes,
+// partial ones included, so a trade closed in two steps counts twice.
+// GrossPL is the realised P/L of those closes and NetPL adds financing and
+// takes off commission. All amounts are in the account's home currency.
+type DailyPerformance struct {
+	Day         time.Time
+	From, To    time.Time
+	Trades      int
+	Wins        int
+	WinRate     float64
+	GrossPL     float64
+	NetPL       float64
+	LargestWin  float64
+	LargestLoss float64
+	Financing   float64
+	Commission  float64
+}
+
+// TradingDay returns the start and end of the OANDA trading day for the
+// date of day, which runs from 17:00 New York time on the previous day to
+// 17:00 on that date.
+func TradingDay(day time.Time) (from, to time.Time) {
+	local := day.In(newYork)
+	to = time.Date(local.Year(), local.Month(), local.Day(), tradingDayEndHour, 0, 0, 0, newYork)
+	return to.AddDate(0, 0, -1), to
+}
+
+// DailySummary reports the account's performance over one day. With a nil
+// loc that is the OANDA trading day ending on the date of day, see
+// TradingDay, and Day is that date at midnight New York. Otherwise it is
+// the calendar day of day in loc, from midnight to midnight there, so the
+// summary can follow the account holder's own timezone. A day without
+// activity gives a summary with every count and amount zero.
+func (c *Client) DailySummary(day time.Time, loc *time.Location) (*DailyPerformance, error) {
+	var summary DailyPerformance
+	if loc == nil {
+		summary.From, summary.To = TradingDay(day)
+		summary.Day = time.Date(summary.To.Year(), summary.To.Month(), summary.To.Day(), 0, 0, 0, 0, newYork)
+	} else {
+		local := day.In(loc)
+		summary.Day = time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+		summary.From, summary.To = summary.Day, summary.Day.AddDate(0, 0, 1)
+	}
+	from, to := summary.From, summary.To
+
+	txns, err := c.getTransactions(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	// Trades closed today may have been opened on an earlier day, so closes
+	// are kept even when their entry isn't among today's transactions.
+	journal, err := buildJournal(txns, true)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range journal {
+		summary.Trades++
+		summary.GrossPL += entry.PL
+		summary.Financing += entry.Financing
+		if entry.PL > 0 {
+			summary.Wins++
+		}
+		summary.LargestWin = max(summary.LargestWin, entry.PL)
+		summary.LargestLoss = min(summary.LargestLoss, entry.PL)
+	}
+	if summary.Trades > 0 {
+		summary.WinRate = float64(summary.Wins) / float64(summary.Trades)
+	}
+
+	for _, txn := range txns {
+		switch txn.Type {
+		case "DAILY_FINANCING":
+			financing, err := txn.DailyFinancing()
+			if err != nil {
+				return nil, err
+			}
+			summary.Financing += financing.Financing
+		case "ORDER_FILL":
+			var fields balanceFields
+			if err := txn.Decode(&fields); err != nil {
+				return nil, err
+			}
+			for _, fee := range []string{fields.Commission, fields.GuaranteedExecutionFee} {
+				if fee == "" {
+					continue
+				}
+				amount, err := strconv.ParseFloat(fee, 64)
+				if err != nil {
+					return nil, err
+				}
+				summary.Commission += amount
+			}
+		}
+	}
+
+	summary.NetPL = summary.GrossPL + summary.Financing - summary.Commission
+	return &summary, nil
+}
@@ -0,0 +1,84 @@
+package trader
+
+import (
+	"sync"
+	"time"
+)
+
+const defaultDedupWindow = time.Minute
+
+// orderDedup remembers recent order results by caller-supplied key.
+type orderDedup struct {
+	mu      sync.Mutex
+	window  time.Duration
+	entries map[string]*dedupEntry
+}
+
+type dedupEntry struct {
+	done     chan struct{}
+	response *OrderResponse
+	err      error
+	placedAt time.Time
+}
+
+// WithOrderDedupWindow sets how long placeMarketOrderOnce remembers a key.
+// The default is a minute.
+func WithOrderDedupWindow(window time.Duration) Option {
+	return func(c *Client) {
+		c.dedup.window = window
+	}
+}
+
+// placeMarketOrderOnce is placeMarketOrder keyed by a caller-chosen key,
+// such as strategy name plus bar time. A second call with the same key
+// within the dedup window returns the first call's response instead of
+// placing another order, and a call made while the first is still in
+// flight waits for it. Failed orders aren't remembered, so they can be
+// retried with the same key. This guards against a strategy loop firing
+// twice on one signal; it is in-process only.
+func (c *Client) placeMarketOrderOnce(key string, units int, instrument string, priceBound float32) (*OrderResponse, error) {
+	now := c.clock.Now()
+
+	c.dedup.mu.Lock()
+	if c.dedup.entries == nil {
+		c.dedup.entries = make(map[string]*dedupEntry)
+	}
+	window := c.dedup.window
+	if window <= 0 {
+		window = defaultDedupWindow
+	}
+	for k, entry := range c.dedup.entries {
+		if isClosed(entry.done) && now.Sub(entry.placedAt) >= window {
+			delete(c.dedup.entries, k)
+		}
+	}
+	if entry, ok := c.dedup.entries[key]; ok {
+		c.dedup.mu.Unlock()
+		<-entry.done
+		if entry.err == nil {
+			return entry.response, nil
+		}
+		return c.placeMarketOrderOnce(key, units, instrument, priceBound)
+	}
+	entry := &dedupEntry{done: make(chan struct{}), placedAt: now}
+	c.dedup.entries[key] = entry
+	c.dedup.mu.Unlock()
+
+	entry.response, entry.err = c.placeMarketOrder(units, instrument, priceBound)
+	if entry.err != nil {
+		c.dedup.mu.Lock()
+		delete(c.dedup.entries, key)
+		c.dedup.mu.Unlock()
+	}
+	close(entry.done)
+	return entry.response, entry.err
+}
+
+func isClosed(ch chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,97 @@
+package trader
+
+import (
+	"fmt"
+	"sync"
+)
+
+// PeakStore persists a DrawdownTracker's peak NAV so it survives restarts.
+type PeakStore interface {
+	LoadPeak() (float64, error)
+	SavePeak(peak float64) error
+}
+
+// DrawdownTracker records the running peak of the account NAV and how far
+// the latest NAV sits below it. It is safe for concurrent use.
+type DrawdownTracker struct {
+	mu      sync.Mutex
+	peak    float64
+	current float64
+	updated bool
+	store   PeakStore
+}
+
+// NewDrawdownTracker returns a tracker seeded from store, which may be nil
+// to keep the peak in memory only.
+func NewDrawdownTracker(store PeakStore) (*DrawdownTracker, error) {
+	t := &DrawdownTracker{store: store}
+	if store != nil {
+		peak, err := store.LoadPeak()
+		if err != nil {
+			return nil, fmt.Errorf("loading drawdown peak: %w", err)
+		}
+		t.peak = peak
+	}
+	return t, nil
+}
+
+// Update records a new NAV observation, saving the peak to the store when
+// it rises.
+func (t *DrawdownTracker) Update(nav float64) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.current = nav
+	t.updated = true
+	if nav <= t.peak {
+		return nil
+	}
+	t.peak = nav
+	if t.store != nil {
+		if err := t.store.SavePeak(nav); err != nil {
+			return fmt.Errorf("saving drawdown peak: %w", err)
+		}
+	}
+	return nil
+}
+
+// restorePeak raises the peak to a previously saved value.
+func (t *DrawdownTracker) restorePeak(peak float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.peak = max(t.peak, peak)
+}
+
+func (t *DrawdownTracker) Peak() float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.peak
+}
+
+// CurrentDrawdown returns the percentage the latest NAV is below the peak,
+// or zero before the first update. A peak restored from a store has no NAV
+// to compare with until Update is called, so it reads as no drawdown.
+func (t *DrawdownTracker) CurrentDrawdown() float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.updated || t.peak <= 0 {
+		return 0
+	}
+	return (t.peak - t.current) / t.peak * 100
+}
+
+// Breached reports whether the current drawdown has reached limitPct.
+func (t *DrawdownTracker) Breached(limitPct float64) bool {
+	return t.CurrentDrawdown() >= limitPct
+}
+
+// UpdateDrawdown feeds the account's current NAV into t. Call it on a timer
+// to keep the tracker current.
+func (c *Client) UpdateDrawdown(t *DrawdownTracker) error {
+	summaryResponse, err := c.getAccountSummary()
+	if err != nil {
+		return err
+	}
+	return t.Update(summaryResponse.Account.NAV)
+}
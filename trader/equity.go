@@ -0,0 +1,98 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// EquityPoint is the account balance straight after a transaction.
+type EquityPoint struct {
+	Time          time.Time
+	TransactionID string
+	Balance       float64
+}
+
+// balanceFields are the numeric fields that move the account balance,
+// shared across the fill, financing, transfer and fee transaction types.
+type balanceFields struct {
+	AccountBalance         string `json:"accountBalance"`
+	PL                     string `json:"pl"`
+	Financing              string `json:"financing"`
+	Commission             string `json:"commission"`
+	GuaranteedExecutionFee string `json:"guaranteedExecutionFee"`
+	Amount                 string `json:"amount"`
+}
+
+// BuildEquityCurve replays txns from startingBalance and returns a point for
+// every transaction that changed the balance. The curve is realised equity:
+// unrealised P/L on open trades is not included.
+//
+// Transactions are replayed in ID order whatever order they are passed in.
+// Where a transaction reports the resulting accountBalance that value is
+// used directly, so gaps from missing transactions are corrected at the next
+// one that carries a balance; otherwise its P/L, financing, commission and
+// transfer amounts are applied to the running balance. Transactions that
+// can't be decoded are logged and skipped.
+func BuildEquityCurve(txns []Transaction, startingBalance float64) ([]EquityPoint, error) {
+	ordered := slices.Clone(txns)
+	sortTransactions(ordered)
+
+	balance := startingBalance
+	var curve []EquityPoint
+	for _, txn := range ordered {
+		var fields balanceFields
+		if err := txn.Decode(&fields); err != nil {
+			log.Printf("Skipping transaction %s in equity curve: %v", txn.ID, err)
+			continue
+		}
+
+		next, changed, err := fields.apply(balance)
+		if err != nil {
+			return nil, fmt.Errorf("transaction %s: %w", txn.ID, err)
+		}
+		if !changed {
+			continue
+		}
+
+		t, err := time.Parse(time.RFC3339Nano, txn.Time)
+		if err != nil {
+			return nil, fmt.Errorf("transaction %s has invalid time %q: %w", txn.ID, txn.Time, err)
+		}
+		balance = next
+		curve = append(curve, EquityPoint{Time: t, TransactionID: txn.ID, Balance: balance})
+	}
+
+	return curve, nil
+}
+
+func (f *balanceFields) apply(balance float64) (float64, bool, error) {
+	if f.AccountBalance != "" {
+		next, err := strconv.ParseFloat(f.AccountBalance, 64)
+		return next, err == nil, err
+	}
+
+	// OANDA reports commission and fees as positive amounts that reduce
+	// the balance; the other fields are signed.
+	changed := false
+	for _, delta := range []struct {
+		value string
+		sign  float64
+	}{
+		{f.PL, 1}, {f.Financing, 1}, {f.Amount, 1},
+		{f.Commission, -1}, {f.GuaranteedExecutionFee, -1},
+	} {
+		if delta.value == "" {
+			continue
+		}
+		value, err := strconv.ParseFloat(delta.value, 64)
+		if err != nil {
+			return balance, false, err
+		}
+		balance += delta.sign * value
+		changed = true
+	}
+	return balance, changed, nil
+}
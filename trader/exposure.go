@@ -0,0 +1,111 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// NetCurrencyExposure splits every open position into its two currency legs
+// and sums them per currency, valued in the account's home currency.
+//
+// A position of u units in BASE_QUOTE at mid price p is long u of BASE and
+// short u*p of QUOTE, so a positive value means the account gains if that
+// currency strengthens against home and a negative value that it loses.
+// Both legs are valued with the quote currency's position-value conversion
+// factor, which also covers metals and indices whose "base" OANDA does not
+// convert. Holding GBP_USD long and EUR_GBP short, for example, shows up as
+// a combined positive GBP exposure.
+func (c *Client) NetCurrencyExposure(ctx context.Context) (map[string]float64, error) {
+	positions, err := c.getOpenPositions()
+	if err != nil {
+		return nil, err
+	}
+
+	exposure := make(map[string]float64)
+	if len(positions) == 0 {
+		return exposure, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	instruments := make([]string, len(positions))
+	for i, position := range positions {
+		instruments[i] = position.Instrument
+	}
+	pricesResponse, err := c.getPrices(instruments, PricingOptions{IncludeHomeConversions: true})
+	if err != nil {
+		return nil, err
+	}
+
+	mids := make(map[string]float64, len(pricesResponse.Prices))
+	for _, price := range pricesResponse.Prices {
+		mids[price.Instrument] = float64(price.Bid+price.Ask) / 2
+	}
+	factors := make(map[string]float64, len(pricesResponse.HomeConversions))
+	for _, conversion := range pricesResponse.HomeConversions {
+		factors[conversion.Currency] = conversion.PositionValue
+	}
+
+	for _, position := range positions {
+		base, quote, ok := strings.Cut(position.Instrument, "_")
+		if !ok {
+			return nil, fmt.Errorf("cannot split instrument %s into currencies", position.Instrument)
+		}
+		mid, ok := mids[position.Instrument]
+		if !ok {
+			return nil, fmt.Errorf("no price received for %s", position.Instrument)
+		}
+		factor, ok := factors[quote]
+		if !ok {
+			return nil, fmt.Errorf("no home conversion received for %s", quote)
+		}
+
+		units := position.NetUnits
+		value := units * mid * factor
+		exposure[base] += value
+		exposure[quote] -= value
+	}
+
+	return exposure, nil
+}
+
+// OrderNotional returns the size of an order of units in instrument, valued
+// in the account's home currency:
+//
+//	|units| * mid * positionValue(QUOTE)
+//
+// where mid is the current mid price of BASE_QUOTE and positionValue is
+// OANDA's conversion factor from the quote currency to home. For a cross
+// such as EUR_GBP on a USD account this is the euro amount priced in pounds
+// and then converted to dollars; when the quote currency is home the factor
+// is 1 and no conversions are requested. The result is the same for buys
+// and sells.
+func (c *Client) OrderNotional(instrumentName string, units int) (float64, error) {
+	instrument, err := c.instrument(instrumentName)
+	if err != nil {
+		return 0, err
+	}
+	_, quote, ok := strings.Cut(instrument.Name, "_")
+	if !ok {
+		return 0, fmt.Errorf("cannot split instrument %s into currencies", instrument.Name)
+	}
+
+	isHome := c.quoteIsHome(quote)
+	pricesResponse, err := c.getPrices([]string{instrument.Name}, PricingOptions{IncludeHomeConversions: !isHome})
+	if err != nil {
+		return 0, err
+	}
+	if len(pricesResponse.Prices) == 0 {
+		return 0, fmt.Errorf("no price received for %s", instrument.Name)
+	}
+	price := pricesResponse.Prices[0]
+	mid := float64(price.Bid+price.Ask) / 2
+
+	conversion, err := homeConversion(quote, isHome, pricesResponse.HomeConversions)
+	if err != nil {
+		return 0, err
+	}
+	return float64(abs(units)) * mid * conversion.PositionValue, nil
+}
@@ -0,0 +1,137 @@
+package trader
+
+import (
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Doer sends HTTP requests for the Client. *http.Client satisfies it; tests
+// can replace it with WithDoer to serve canned responses or, wrapped in a
+// FaultyDoer, to inject latency and failures.
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// WithDoer sends every request, streams included, through d instead of the
+// default *http.Client. WithConnectionPool has no effect on a Doer that
+// isn't an *http.Client.
+func WithDoer(d Doer) Option {
+	return func(c *Client) {
+		c.httpClient = d
+	}
+}
+
+// FaultConfig describes artificial latency and failures. Each call waits a
+// uniformly random time between MinLatency and MaxLatency, then fails with
+// probability FailureRate. Calls with the same Seed see the same sequence of
+// delays and failures. A FaultyDoer waits on Clock, or the real clock if it
+// is nil; a SimClient always uses its own clock.
+type FaultConfig struct {
+	MinLatency  time.Duration
+	MaxLatency  time.Duration
+	FailureRate float64
+	Seed        int64
+	Clock       Clock
+}
+
+// ErrSimulatedTimeout is returned by injected failures. It reports itself as
+// a timeout, so the Client's retries treat it like a real one.
+var ErrSimulatedTimeout error = simulatedTimeout{}
+
+type simulatedTimeout struct{}
+
+func (simulatedTimeout) Error() string   { return "simulated timeout" }
+func (simulatedTimeout) Timeout() bool   { return true }
+func (simulatedTimeout) Temporary() bool { return true }
+
+type faultInjector struct {
+	mu  sync.Mutex
+	cfg FaultConfig
+	rng *rand.Rand
+}
+
+func newFaultInjector(cfg FaultConfig) *faultInjector {
+	return &faultInjector{cfg: cfg, rng: rand.New(rand.NewSource(cfg.Seed))}
+}
+
+// next draws the delay and outcome of one call.
+func (f *faultInjector) next() (time.Duration, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delay := f.cfg.MinLatency
+	if spread := f.cfg.MaxLatency - f.cfg.MinLatency; spread > 0 {
+		delay += time.Duration(f.rng.Int63n(int64(spread) + 1))
+	}
+	return delay, f.rng.Float64() < f.cfg.FailureRate
+}
+
+// FaultyDoer wraps a Doer with the latency and failures of a FaultConfig.
+// A request whose context ends during the delay fails with the context's
+// error, as a real slow request would.
+type FaultyDoer struct {
+	next   Doer
+	faults *faultInjector
+}
+
+func NewFaultyDoer(next Doer, cfg FaultConfig) *FaultyDoer {
+	return &FaultyDoer{next: next, faults: newFaultInjector(cfg)}
+}
+
+func (d *FaultyDoer) Do(req *http.Request) (*http.Response, error) {
+	delay, fail := d.faults.next()
+	if delay > 0 {
+		clock := d.faults.cfg.Clock
+		if clock == nil {
+			clock = realClock{}
+		}
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-clock.After(delay):
+		}
+	}
+	if fail {
+		return nil, ErrSimulatedTimeout
+	}
+	return d.next.Do(req)
+}
+
+// WithSimulatedLatency delays every SimClient order by a random time
+// between minLatency and maxLatency on the SimClient's clock, outside its
+// lock so concurrent orders interleave.
+func WithSimulatedLatency(minLatency, maxLatency time.Duration) SimOption {
+	return func(s *SimClient) {
+		s.faultConfig.MinLatency, s.faultConfig.MaxLatency = minLatency, maxLatency
+	}
+}
+
+// WithSimulatedFailures fails a fraction rate of SimClient orders with
+// ErrSimulatedTimeout, after any simulated latency and without filling.
+func WithSimulatedFailures(rate float64) SimOption {
+	return func(s *SimClient) {
+		s.faultConfig.FailureRate = rate
+	}
+}
+
+// WithSimSeed seeds the simulated latency and failures for reproducible
+// runs. The default seed is 0.
+func WithSimSeed(seed int64) SimOption {
+	return func(s *SimClient) {
+		s.faultConfig.Seed = seed
+	}
+}
+
+// injectFaults applies the simulated latency and failure of one order.
+func (s *SimClient) injectFaults() error {
+	delay, fail := s.faults.next()
+	if delay > 0 {
+		<-s.clock.After(delay)
+	}
+	if fail {
+		return ErrSimulatedTimeout
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package trader
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Fill is the flattened result of an order. Filled is false when the order
+// was accepted but not filled, such as a pending limit order or a market
+// order cancelled at its price bound, in which case only Instrument and
+// TransactionID, the order's create transaction, are set. TradeID is the
+// trade opened, or the first trade reduced or closed when the fill only
+// cut an existing position. PL is the realised profit or loss in the
+// account's home currency.
+type Fill struct {
+	Filled        bool
+	Instrument    string
+	Units         int
+	FillPrice     float64
+	TradeID       string
+	TransactionID string
+	PL            float64
+}
+
+// FillSummary parses the fill in r into a Fill.
+func (r *OrderResponse) FillSummary() (*Fill, error) {
+	fillTx := r.OrderFillTransaction
+	if fillTx.ID == "" {
+		return &Fill{Instrument: r.OrderCreateTransaction.Instrument, TransactionID: r.OrderCreateTransaction.ID}, nil
+	}
+
+	units, err := strconv.ParseFloat(fillTx.Units, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid units %q in fill %s: %w", fillTx.Units, fillTx.ID, err)
+	}
+	price, err := strconv.ParseFloat(fillTx.Price, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid price %q in fill %s: %w", fillTx.Price, fillTx.ID, err)
+	}
+	pl, err := parseOptionalFloat(fillTx.Pl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid P/L %q in fill %s: %w", fillTx.Pl, fillTx.ID, err)
+	}
+
+	tradeID := fillTx.TradeOpened.TradeID
+	switch {
+	case tradeID != "":
+	case fillTx.TradeReduced != nil:
+		tradeID = fillTx.TradeReduced.TradeID
+	case len(fillTx.TradesClosed) > 0:
+		tradeID = fillTx.TradesClosed[0].TradeID
+	}
+
+	return &Fill{
+		Filled:        true,
+		Instrument:    fillTx.Instrument,
+		Units:         int(units),
+		FillPrice:     price,
+		TradeID:       tradeID,
+		TransactionID: fillTx.ID,
+		PL:            pl,
+	}, nil
+}
@@ -0,0 +1,109 @@
+package trader
+
+import (
+	"fmt"
+	"time"
+)
+
+// InstrumentFinancing is an instrument's financing configuration. LongRate
+// and ShortRate are annual rates as fractions, negative when the holder
+// pays, charged on DaysCharged days for each DayOfWeek rollover.
+type InstrumentFinancing struct {
+	LongRate            float64              `json:"longRate,string"`
+	ShortRate           float64              `json:"shortRate,string"`
+	FinancingDaysOfWeek []FinancingDayOfWeek `json:"financingDaysOfWeek"`
+}
+
+type FinancingDayOfWeek struct {
+	DayOfWeek   string `json:"dayOfWeek"`
+	DaysCharged int    `json:"daysCharged"`
+}
+
+// DailyFinancingTransaction is the swap charged or paid on open positions at
+// the daily rollover. Amounts are in the account's home currency.
+type DailyFinancingTransaction struct {
+	ID                 string              `json:"id"`
+	Time               string              `json:"time"`
+	Type               string              `json:"type"`
+	Financing          float64             `json:"financing,string"`
+	AccountBalance     float64             `json:"accountBalance,string"`
+	PositionFinancings []PositionFinancing `json:"positionFinancings"`
+}
+
+type PositionFinancing struct {
+	Instrument          string           `json:"instrument"`
+	Financing           float64          `json:"financing,string"`
+	OpenTradeFinancings []TradeFinancing `json:"openTradeFinancings"`
+}
+
+type TradeFinancing struct {
+	TradeID   string  `json:"tradeID"`
+	Financing float64 `json:"financing,string"`
+}
+
+// DailyFinancing decodes a DAILY_FINANCING transaction.
+func (t *Transaction) DailyFinancing() (*DailyFinancingTransaction, error) {
+	if t.Type != "DAILY_FINANCING" {
+		return nil, fmt.Errorf("transaction %s is %s, not DAILY_FINANCING", t.ID, t.Type)
+	}
+
+	var financing DailyFinancingTransaction
+	if err := t.Decode(&financing); err != nil {
+		return nil, err
+	}
+	return &financing, nil
+}
+
+// FinancingRate returns the annual financing rate for holding instrument on
+// side, as a fraction: -0.02 means 2% a year of the position's value is
+// paid. It is an error if the instrument has no financing configured.
+func (c *Client) FinancingRate(instrumentName string, side Side) (float64, error) {
+	instrument, err := c.instrument(instrumentName)
+	if err != nil {
+		return 0, err
+	}
+	if instrument.Financing == nil {
+		return 0, fmt.Errorf("no financing configured for %s", instrumentName)
+	}
+
+	switch side {
+	case SideLong:
+		return instrument.Financing.LongRate, nil
+	case SideShort:
+		return instrument.Financing.ShortRate, nil
+	default:
+		return 0, fmt.Errorf("invalid side %q", side)
+	}
+}
+
+func (c *Client) getFinancingTransactions(from, to time.Time) ([]DailyFinancingTransaction, error) {
+	txns, err := c.getTransactions(from, to, "DAILY_FINANCING")
+	if err != nil {
+		return nil, err
+	}
+
+	financings := make([]DailyFinancingTransaction, 0, len(txns))
+	for _, txn := range txns {
+		financing, err := txn.DailyFinancing()
+		if err != nil {
+			return nil, err
+		}
+		financings = append(financings, *financing)
+	}
+	return financings, nil
+}
+
+// TotalFinancing sums the daily financing between from and to. A negative
+// total means swap was paid.
+func (c *Client) TotalFinancing(from, to time.Time) (float64, error) {
+	financings, err := c.getFinancingTransactions(from, to)
+	if err != nil {
+		return 0, err
+	}
+
+	var total float64
+	for _, financing := range financings {
+		total += financing.Financing
+	}
+	return total, nil
+}
@@ -0,0 +1,89 @@
+package trader
+
+import (
+	"fmt"
+	"time"
+)
+
+// Gap is a run of bars missing from a candle series. Start and End are the
+// times of the candles either side of it, and Missing is how many bars
+// should have opened between them given the instrument's trading sessions.
+// Expected is when the first missing bar should have opened and Actual when
+// the next bar did, which is End.
+type Gap struct {
+	Start    time.Time
+	End      time.Time
+	Missing  int
+	Expected time.Time
+	Actual   time.Time
+}
+
+// DetectGaps returns the gaps in candles, which must be in time order, that
+// the trading sessions don't explain. A bar is expected when the instrument
+// trades at some point during it, so the FX weekend and the daily break of
+// metals and CFDs are expected and a Friday close followed by a Sunday open
+// isn't a gap. Bars that open on one of holidays, a trading day that starts
+// at 17:00 New York the evening before, aren't expected either; only the
+// date of each holiday is used. Quiet bars OANDA skipped because nothing
+// traded still show up as gaps.
+//
+// Daily and weekly bars step by calendar day in New York rather than a fixed
+// 24 hours, so bars aligned to 17:00 New York, OANDA's default, stay aligned
+// across daylight saving changes.
+func DetectGaps(instrument string, candles []Candle, granularity string, holidays []time.Time) ([]Gap, error) {
+	period, ok := granularityDurations[granularity]
+	if !ok {
+		return nil, fmt.Errorf("gap detection does not support granularity %q", granularity)
+	}
+	step := func(t time.Time) time.Time { return t.Add(period) }
+	switch granularity {
+	case "D":
+		step = func(t time.Time) time.Time { return t.In(newYork).AddDate(0, 0, 1) }
+	case "W":
+		step = func(t time.Time) time.Time { return t.In(newYork).AddDate(0, 0, 7) }
+	}
+
+	closed := make(map[civilDate]bool, len(holidays))
+	for _, holiday := range holidays {
+		closed[civilDate{holiday.Year(), holiday.Month(), holiday.Day()}] = true
+	}
+
+	var gaps []Gap
+	for i := 1; i < len(candles); i++ {
+		prev, next := candles[i-1].Time, candles[i].Time
+		if !next.After(prev) {
+			return nil, fmt.Errorf("candle at %s is not after the one at %s", next, prev)
+		}
+
+		gap := Gap{Start: prev, End: next, Actual: next}
+		for t := step(prev); t.Before(next); t = step(t) {
+			if closed[tradingDay(t)] || !NextOpen(instrument, t).Before(step(t)) {
+				continue
+			}
+			if gap.Missing == 0 {
+				gap.Expected = t
+			}
+			gap.Missing++
+		}
+		if gap.Missing > 0 {
+			gaps = append(gaps, gap)
+		}
+	}
+	return gaps, nil
+}
+
+type civilDate struct {
+	year  int
+	month time.Month
+	day   int
+}
+
+// tradingDay returns the FX trading day t falls in, which rolls over at
+// 17:00 New York.
+func tradingDay(t time.Time) civilDate {
+	local := t.In(newYork)
+	if local.Hour() >= 17 {
+		local = local.AddDate(0, 0, 1)
+	}
+	return civilDate{local.Year(), local.Month(), local.Day()}
+}
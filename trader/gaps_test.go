@@ -0,0 +1,40 @@
+package trader
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDetectGapsDaily(t *testing.T) {
+	day := func(month time.Month, d int) Candle {
+		return Candle{Time: time.Date(2024, month, d, 17, 0, 0, 0, newYork)}
+	}
+
+	// US clocks went forward on Sunday 10 March 2024.
+	acrossDST := []Candle{day(3, 7), day(3, 10), day(3, 11), day(3, 12)}
+	gaps, err := DetectGaps("EUR_USD", acrossDST, "D", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gaps) != 0 {
+		t.Errorf("got gaps %+v across the DST change, want none", gaps)
+	}
+
+	missing := []Candle{day(3, 11), day(3, 14)}
+	gaps, err = DetectGaps("EUR_USD", missing, "D", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gaps) != 1 || gaps[0].Missing != 2 || !gaps[0].Expected.Equal(day(3, 12).Time) || !gaps[0].Actual.Equal(day(3, 14).Time) {
+		t.Errorf("got gaps %+v, want two bars missing from the 12th", gaps)
+	}
+
+	holidays := []time.Time{time.Date(2024, 3, 13, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 14, 0, 0, 0, 0, time.UTC)}
+	gaps, err = DetectGaps("EUR_USD", missing, "D", holidays)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gaps) != 0 {
+		t.Errorf("got gaps %+v on holidays, want none", gaps)
+	}
+}
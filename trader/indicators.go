@@ -0,0 +1,216 @@
+package trader
+
+import (
+	"fmt"
+	"math"
+)
+
+// ATR returns the average true range over period, aligned to candles. The
+// true range of a candle is the largest of high-low, |high-prevClose| and
+// |low-prevClose|; the first candle has no previous close so its range is
+// high-low. Following Wilder, the first ATR is the simple mean of the first
+// period true ranges and each later value is
+// (prevATR*(period-1) + TR) / period. Indexes before period-1 are NaN.
+// Mid prices are used.
+func ATR(candles []Candle, period int) ([]float64, error) {
+	if period <= 0 {
+		return nil, fmt.Errorf("ATR period must be positive, got %d", period)
+	}
+	if len(candles) < period {
+		return nil, fmt.Errorf("ATR(%d) needs at least %d candles, got %d", period, period, len(candles))
+	}
+
+	atr := make([]float64, len(candles))
+	var sum float64
+	for i, candle := range candles {
+		if candle.Mid == nil {
+			return nil, fmt.Errorf("candle at %s has no mid prices", candle.Time)
+		}
+
+		high, low := float64(candle.Mid.High), float64(candle.Mid.Low)
+		trueRange := high - low
+		if i > 0 {
+			prevClose := float64(candles[i-1].Mid.Close)
+			trueRange = max(trueRange, math.Abs(high-prevClose), math.Abs(low-prevClose))
+		}
+
+		switch {
+		case i < period-1:
+			sum += trueRange
+			atr[i] = math.NaN()
+		case i == period-1:
+			sum += trueRange
+			atr[i] = sum / float64(period)
+		default:
+			atr[i] = (atr[i-1]*float64(period-1) + trueRange) / float64(period)
+		}
+	}
+
+	return atr, nil
+}
+
+// EWMAVolatility returns the exponentially weighted volatility of mid close
+// log returns, aligned to candles. The variance is seeded with the square of
+// the first return and then updated as
+// lambda*prevVariance + (1-lambda)*r^2, so index 0 is NaN and early values
+// are noisy. RiskMetrics uses a lambda of 0.94 for daily data. Values are per
+// bar, not annualised.
+func EWMAVolatility(candles []Candle, lambda float64) ([]float64, error) {
+	if lambda <= 0 || lambda >= 1 {
+		return nil, fmt.Errorf("EWMA lambda must be between 0 and 1, got %g", lambda)
+	}
+	if len(candles) < 2 {
+		return nil, fmt.Errorf("EWMA volatility needs at least 2 candles, got %d", len(candles))
+	}
+
+	vol := make([]float64, len(candles))
+	vol[0] = math.NaN()
+	var variance float64
+	for i := 1; i < len(candles); i++ {
+		prev, cur := candles[i-1].Mid, candles[i].Mid
+		if prev == nil || cur == nil {
+			return nil, fmt.Errorf("candle at %s has no mid prices", candles[i].Time)
+		}
+
+		r := math.Log(float64(cur.Close) / float64(prev.Close))
+		if i == 1 {
+			variance = r * r
+		} else {
+			variance = lambda*variance + (1-lambda)*r*r
+		}
+		vol[i] = math.Sqrt(variance)
+	}
+
+	return vol, nil
+}
+
+// SMA returns the simple moving average over period, aligned to values.
+// Indexes before period-1 are NaN.
+func SMA(values []float64, period int) ([]float64, error) {
+	if period <= 0 {
+		return nil, fmt.Errorf("SMA period must be positive, got %d", period)
+	}
+	if len(values) < period {
+		return nil, fmt.Errorf("SMA(%d) needs at least %d values, got %d", period, period, len(values))
+	}
+
+	sma := make([]float64, len(values))
+	var sum float64
+	for i, v := range values {
+		sum += v
+		if i >= period {
+			sum -= values[i-period]
+		}
+		if i < period-1 {
+			sma[i] = math.NaN()
+		} else {
+			sma[i] = sum / float64(period)
+		}
+	}
+
+	return sma, nil
+}
+
+// EMA returns the exponential moving average over period, aligned to values,
+// using a smoothing factor of 2/(period+1). It is seeded with the SMA of the
+// first period values, so indexes before period-1 are NaN.
+func EMA(values []float64, period int) ([]float64, error) {
+	ema, err := SMA(values, period)
+	if err != nil {
+		return nil, err
+	}
+
+	alpha := 2 / float64(period+1)
+	for i := period; i < len(values); i++ {
+		ema[i] = alpha*values[i] + (1-alpha)*ema[i-1]
+	}
+
+	return ema, nil
+}
+
+// Closes returns the mid close of each candle, for feeding the moving
+// averages. Candles without mid prices yield NaN.
+func Closes(candles []Candle) []float64 {
+	closes := make([]float64, len(candles))
+	for i, candle := range candles {
+		if candle.Mid == nil {
+			closes[i] = math.NaN()
+			continue
+		}
+		closes[i] = float64(candle.Mid.Close)
+	}
+	return closes
+}
+
+// CrossOver returns +1 at each index where fast crosses above slow, -1 where
+// it crosses below and 0 elsewhere. The result has the length of the shorter
+// input. A cross is measured against the last index where the two series
+// differed, so touching and separating again on the same side is not a
+// cross. NaN values, such as moving average warm-up, yield 0 and are skipped.
+func CrossOver(fast, slow []float64) []int {
+	signals := make([]int, min(len(fast), len(slow)))
+	lastSide := 0
+	for i := range signals {
+		if math.IsNaN(fast[i]) || math.IsNaN(slow[i]) {
+			continue
+		}
+
+		side := 0
+		switch {
+		case fast[i] > slow[i]:
+			side = 1
+		case fast[i] < slow[i]:
+			side = -1
+		}
+		if side == 0 {
+			continue
+		}
+
+		if lastSide != 0 && side != lastSide {
+			signals[i] = side
+		}
+		lastSide = side
+	}
+
+	return signals
+}
+
+// SpreadSeries returns the closing spread of each bar, the ask close minus
+// the bid close, in price terms. bidCandles and askCandles must cover the
+// same bars; candles fetched with Price "BA" can be passed as both.
+func SpreadSeries(bidCandles, askCandles []Candle) ([]float64, error) {
+	if len(bidCandles) != len(askCandles) {
+		return nil, fmt.Errorf("got %d bid candles and %d ask candles", len(bidCandles), len(askCandles))
+	}
+
+	spreads := make([]float64, len(bidCandles))
+	for i := range bidCandles {
+		bid, ask := bidCandles[i], askCandles[i]
+		if !bid.Time.Equal(ask.Time) {
+			return nil, fmt.Errorf("bid candle at %s does not line up with ask candle at %s", bid.Time, ask.Time)
+		}
+		if bid.Bid == nil || ask.Ask == nil {
+			return nil, fmt.Errorf("candle at %s is missing bid or ask prices", bid.Time)
+		}
+		spreads[i] = float64(ask.Ask.Close) - float64(bid.Bid.Close)
+	}
+
+	return spreads, nil
+}
+
+// SpreadSeriesPips is SpreadSeries in pips of pipSize, see
+// Instrument.PipSize.
+func SpreadSeriesPips(bidCandles, askCandles []Candle, pipSize float64) ([]float64, error) {
+	if pipSize <= 0 {
+		return nil, fmt.Errorf("pip size must be positive, got %g", pipSize)
+	}
+
+	spreads, err := SpreadSeries(bidCandles, askCandles)
+	if err != nil {
+		return nil, err
+	}
+	for i := range spreads {
+		spreads[i] /= pipSize
+	}
+	return spreads, nil
+}
@@ -0,0 +1,73 @@
+package trader
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestCrossOver(t *testing.T) {
+	nan := math.NaN()
+	tests := []struct {
+		name       string
+		fast, slow []float64
+		want       []int
+	}{
+		{
+			name: "cross above",
+			fast: []float64{1, 2, 3},
+			slow: []float64{2, 2, 2},
+			want: []int{0, 0, 1},
+		},
+		{
+			name: "cross below",
+			fast: []float64{3, 2, 1},
+			slow: []float64{2, 2, 2},
+			want: []int{0, 0, -1},
+		},
+		{
+			name: "both directions",
+			fast: []float64{1, 3, 1, 3},
+			slow: []float64{2, 2, 2, 2},
+			want: []int{0, 1, -1, 1},
+		},
+		{
+			name: "touch then separate on the same side",
+			fast: []float64{1, 2, 1},
+			slow: []float64{2, 2, 2},
+			want: []int{0, 0, 0},
+		},
+		{
+			name: "touch then cross",
+			fast: []float64{1, 2, 2, 3},
+			slow: []float64{2, 2, 2, 2},
+			want: []int{0, 0, 0, 1},
+		},
+		{
+			name: "NaN warm-up",
+			fast: []float64{nan, nan, 1, 3},
+			slow: []float64{nan, 2, 2, 2},
+			want: []int{0, 0, 0, 1},
+		},
+		{
+			name: "NaN gap keeps the last side",
+			fast: []float64{3, nan, 1},
+			slow: []float64{2, 2, 2},
+			want: []int{0, 0, -1},
+		},
+		{
+			name: "shorter input sets the length",
+			fast: []float64{1, 3, 1},
+			slow: []float64{2, 2},
+			want: []int{0, 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CrossOver(tt.fast, tt.slow); !slices.Equal(got, tt.want) {
+				t.Errorf("CrossOver(%v, %v) = %v, want %v", tt.fast, tt.slow, got, tt.want)
+			}
+		})
+	}
+}
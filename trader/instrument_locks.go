@@ -0,0 +1,61 @@
+package trader
+
+import "sync"
+
+// instrumentLocks serialises order submission per instrument. Orders in
+// one instrument are sent one at a time, including their pre-trade checks
+// and post-fill hooks, while orders in different instruments run in
+// parallel.
+//
+// The lock is held for the whole submission, so a pre-trade check or
+// post-fill hook that places an order in the same instrument deadlocks.
+// Closing trades and positions doesn't take the lock.
+type instrumentLocks struct {
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+// lock locks instrument and returns the function that unlocks it. Names
+// are normalised first, so "eur/usd" and "EUR_USD" share a lock.
+func (l *instrumentLocks) lock(instrument string) func() {
+	instrument = normalizeInstrument(instrument)
+
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = make(map[string]*sync.Mutex)
+	}
+	lock, ok := l.locks[instrument]
+	if !ok {
+		lock = &sync.Mutex{}
+		l.locks[instrument] = lock
+	}
+	l.mu.Unlock()
+
+	lock.Lock()
+	return lock.Unlock
+}
+
+// defaultMaxConcurrentOrders caps the order requests in flight at once
+// unless WithMaxConcurrentOrders says otherwise.
+const defaultMaxConcurrentOrders = 4
+
+// WithMaxConcurrentOrders allows at most n order requests to OANDA in
+// flight at once across all instruments, 4 by default. Further orders wait
+// for a slot, after their pre-trade checks. Helpers that place several
+// orders still return results in the order given. n of zero or less
+// removes the limit.
+func WithMaxConcurrentOrders(n int) Option {
+	return func(c *Client) {
+		c.maxConcurrentOrders = n
+	}
+}
+
+// acquireOrderSlot waits for a free order slot and returns the function
+// that releases it.
+func (c *Client) acquireOrderSlot() func() {
+	if c.orderSlots == nil {
+		return func() {}
+	}
+	c.orderSlots <- struct{}{}
+	return func() { <-c.orderSlots }
+}
@@ -0,0 +1,57 @@
+package trader
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrInstrumentNotAllowed = errors.New("instrument is not allowed")
+
+// WithAllowedInstruments restricts orders to the given instruments. Orders
+// in anything else fail with ErrInstrumentNotAllowed before any request is
+// made. Repeated options add to the list.
+func WithAllowedInstruments(instruments []string) Option {
+	return func(c *Client) {
+		if c.allowedInstruments == nil {
+			c.allowedInstruments = make(map[string]bool)
+		}
+		for _, instrument := range instruments {
+			c.allowedInstruments[normalizeInstrument(instrument)] = true
+		}
+	}
+}
+
+// WithDeniedInstruments refuses orders in the given instruments with
+// ErrInstrumentNotAllowed. The deny list wins over WithAllowedInstruments.
+func WithDeniedInstruments(instruments []string) Option {
+	return func(c *Client) {
+		if c.deniedInstruments == nil {
+			c.deniedInstruments = make(map[string]bool)
+		}
+		for _, instrument := range instruments {
+			c.deniedInstruments[normalizeInstrument(instrument)] = true
+		}
+	}
+}
+
+// WithRestrictedPricing applies the allow and deny lists to price requests
+// as well as orders. Without it any instrument can be priced.
+func WithRestrictedPricing() Option {
+	return func(c *Client) {
+		c.restrictPricing = true
+	}
+}
+
+// normalizeInstrument turns names like "eur/usd" into OANDA's EUR_USD form.
+func normalizeInstrument(instrument string) string {
+	return strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(instrument), "/", "_"))
+}
+
+func (c *Client) checkInstrumentAllowed(instrument string) error {
+	name := normalizeInstrument(instrument)
+	if c.deniedInstruments[name] || (c.allowedInstruments != nil && !c.allowedInstruments[name]) {
+		return fmt.Errorf("%w: %s", ErrInstrumentNotAllowed, instrument)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"net/url"
+	"strings"
+)
+
+const instrumentsEndpoint = "/v3/accounts/{accountID}/instruments"
+
+type InstrumentsResponse struct {
+	Instruments       []Instrument `json:"instruments"`
+	LastTransactionID string       `json:"lastTransactionID"`
+}
+
+type Instrument struct {
+	Name                        string  `json:"name"`
+	Type                        string  `json:"type"`
+	DisplayName                 string  `json:"displayName"`
+	PipLocation                 int     `json:"pipLocation"`
+	DisplayPrecision            int     `json:"displayPrecision"`
+	TradeUnitsPrecision         int     `json:"tradeUnitsPrecision"`
+	MinimumTradeSize            float64 `json:"minimumTradeSize,string"`
+	MaximumTrailingStopDistance float64 `json:"maximumTrailingStopDistance,string"`
+	MinimumTrailingStopDistance float64 `json:"minimumTrailingStopDistance,string"`
+	MaximumPositionSize         float64 `json:"maximumPositionSize,string"`
+	MaximumOrderUnits           float64 `json:"maximumOrderUnits,string"`
+	MarginRate                  float64 `json:"marginRate,string"`
+
+	// Financing is nil for instruments OANDA doesn't charge financing on.
+	Financing *InstrumentFinancing `json:"financing"`
+}
+
+// PipSize is the price movement of one pip, e.g. 0.0001 for EUR_USD.
+func (i *Instrument) PipSize() float64 {
+	return math.Pow10(i.PipLocation)
+}
+
+func (i *Instrument) formatPrice(price float64) string {
+	return fmt.Sprintf("%.*f", i.DisplayPrecision, price)
+}
+
+func (c *Client) getInstruments(instruments []string) ([]Instrument, error) {
+	q := url.Values{}
+	if len(instruments) > 0 {
+		q.Add("instruments", strings.Join(instruments, ","))
+	}
+
+	var instrumentsResponse InstrumentsResponse
+	err := c.do(context.Background(), "GET", instrumentsEndpoint, q, nil, 200, &instrumentsResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	if c.instruments == nil {
+		c.instruments = make(map[string]Instrument)
+	}
+	for _, instrument := range instrumentsResponse.Instruments {
+		c.instruments[instrument.Name] = instrument
+	}
+	c.mu.Unlock()
+	return instrumentsResponse.Instruments, nil
+}
+
+// instrument returns the metadata for name, fetching it with getInstruments
+// the first time and caching it after. Instrument metadata rarely changes.
+func (c *Client) instrument(name string) (*Instrument, error) {
+	c.mu.Lock()
+	instrument, ok := c.instruments[name]
+	c.mu.Unlock()
+	if ok {
+		return &instrument, nil
+	}
+
+	instruments, err := c.getInstruments([]string{name})
+	if err != nil {
+		return nil, err
+	}
+	for _, instrument := range instruments {
+		if instrument.Name == name {
+			return &instrument, nil
+		}
+	}
+	return nil, fmt.Errorf("instrument %s not found", name)
+}
+
+// formatPrice formats price at instrumentName's display precision, as OANDA
+// rejects prices with more decimal places than the instrument quotes. When
+// the metadata can't be fetched it falls back to pricePrecision.
+func (c *Client) formatPrice(instrumentName string, price float64) string {
+	instrument, err := c.instrument(instrumentName)
+	if err != nil {
+		log.Printf("Error fetching %s metadata, formatting price with default precision: %v", instrumentName, err)
+		return fmt.Sprintf("%.*f", c.pricePrecision(instrumentName), price)
+	}
+	return instrument.formatPrice(price)
+}
+
+// defaultPricePrecision is the display precision of common instruments, as
+// OANDA reports it, for formatting prices without instrument metadata.
+var defaultPricePrecision = map[string]int{
+	"EUR_USD": 5, "GBP_USD": 5, "AUD_USD": 5, "NZD_USD": 5, "USD_CAD": 5, "USD_CHF": 5,
+	"EUR_GBP": 5, "EUR_CHF": 5, "EUR_AUD": 5, "EUR_CAD": 5, "GBP_CHF": 5, "AUD_NZD": 5,
+	"USD_JPY": 3, "EUR_JPY": 3, "GBP_JPY": 3, "AUD_JPY": 3, "NZD_JPY": 3, "CAD_JPY": 3, "CHF_JPY": 3,
+	"XAU_USD": 3, "XAG_USD": 5, "XPT_USD": 3, "XPD_USD": 3,
+	"SPX500_USD": 1, "NAS100_USD": 1, "US30_USD": 1, "UK100_GBP": 1, "DE30_EUR": 1,
+}
+
+// WithPricePrecision sets the decimal places prices of the given
+// instruments are formatted to when their metadata hasn't been fetched,
+// adding to or replacing the built-in defaults. Fetched metadata always
+// takes precedence.
+func WithPricePrecision(precision map[string]int) Option {
+	return func(c *Client) {
+		if c.pricePrecisions == nil {
+			c.pricePrecisions = make(map[string]int)
+		}
+		for instrument, places := range precision {
+			c.pricePrecisions[instrument] = places
+		}
+	}
+}
+
+// defaultPipLocation guesses an instrument's pip location when its metadata
+// hasn't been fetched: JPY crosses and metals quote pips in the second
+// decimal place and other currency pairs in the fourth.
+func defaultPipLocation(instrument string) int {
+	base, quote, _ := strings.Cut(instrument, "_")
+	if quote == "JPY" || metals[base] {
+		return -2
+	}
+	return -4
+}
@@ -0,0 +1,218 @@
+package trader
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// JournalEntry is a closed trade, or the closed part of one, joined to the
+// fill that opened it. Units are the units closed, positive for a long
+// trade and negative for a short one. PL is the realised profit or loss and
+// Financing the financing paid or received on the closed units, both in the
+// account's home currency.
+//
+// RMultiple is the move from entry to exit divided by the initial risk,
+// the distance from the entry price to the stop loss or guaranteed stop
+// loss attached when the trade was filled, so 2 means the trade made twice
+// what it risked and -1 that it was stopped out at its stop. It is measured
+// in price, leaving out financing and currency conversion, and is nil when
+// the trade had no stop on fill or its opening isn't in the transactions.
+// Stops added or moved later are ignored.
+type JournalEntry struct {
+	TradeID       string
+	Instrument    string
+	EntryTime     time.Time
+	EntryPrice    float64
+	ExitTime      time.Time
+	ExitPrice     float64
+	Units         float64
+	PL            float64
+	Financing     float64
+	HoldingPeriod time.Duration
+	// Partial is set when the close left part of the trade open.
+	Partial   bool
+	RMultiple *float64
+}
+
+type openedTrade struct {
+	instrument string
+	time       time.Time
+	price      float64
+	remaining  float64
+	// risk is the distance to the initial stop, zero when there was none.
+	risk float64
+}
+
+// BuildJournal links each ORDER_FILL that opened a trade with the fills
+// that later reduced or closed it, in ID order. A trade closed in several
+// steps gives one entry per step. Trades still open produce no entry, and
+// closes of trades opened before the first transaction in txns are skipped
+// because their entry isn't known.
+func BuildJournal(txns []Transaction) ([]JournalEntry, error) {
+	return buildJournal(txns, false)
+}
+
+// buildJournal is BuildJournal, also keeping closes whose entry isn't in
+// txns when keepUnmatched is set. Those entries have only the exit side and
+// the P/L filled in.
+func buildJournal(txns []Transaction, keepUnmatched bool) ([]JournalEntry, error) {
+	ordered := slices.Clone(txns)
+	sortTransactions(ordered)
+
+	opened := make(map[string]*openedTrade)
+	var journal []JournalEntry
+	for _, txn := range ordered {
+		if txn.Type == "STOP_LOSS_ORDER" || txn.Type == "GUARANTEED_STOP_LOSS_ORDER" {
+			if err := recordInitialStop(txn, opened); err != nil {
+				return nil, fmt.Errorf("transaction %s: %w", txn.ID, err)
+			}
+			continue
+		}
+		if txn.Type != "ORDER_FILL" {
+			continue
+		}
+
+		var fill OrderFillTransaction
+		if err := txn.Decode(&fill); err != nil {
+			return nil, fmt.Errorf("transaction %s: %w", txn.ID, err)
+		}
+		t, err := time.Parse(time.RFC3339Nano, fill.Time)
+		if err != nil {
+			return nil, fmt.Errorf("transaction %s has invalid time %q: %w", txn.ID, fill.Time, err)
+		}
+
+		// A fill closes and reduces trades before opening one with what is
+		// left over.
+		reductions := slices.Clone(fill.TradesClosed)
+		if fill.TradeReduced != nil {
+			reductions = append(reductions, *fill.TradeReduced)
+		}
+		for _, reduce := range reductions {
+			trade, ok := opened[reduce.TradeID]
+			if !ok && !keepUnmatched {
+				continue
+			}
+			if !ok {
+				trade = &openedTrade{instrument: fill.Instrument, time: t}
+			}
+			entry, err := trade.close(reduce, fill.Price, t)
+			if err != nil {
+				return nil, fmt.Errorf("transaction %s: %w", txn.ID, err)
+			}
+			entry.TradeID = reduce.TradeID
+			journal = append(journal, *entry)
+			if !entry.Partial {
+				delete(opened, reduce.TradeID)
+			}
+		}
+
+		if fill.TradeOpened.TradeID != "" {
+			trade, err := openTrade(&fill, t)
+			if err != nil {
+				return nil, fmt.Errorf("transaction %s: %w", txn.ID, err)
+			}
+			opened[fill.TradeOpened.TradeID] = trade
+		}
+	}
+
+	return journal, nil
+}
+
+// recordInitialStop sets the risk of the trade a stop was attached to on
+// fill. Stops created for any other reason are not the initial stop.
+func recordInitialStop(txn Transaction, opened map[string]*openedTrade) error {
+	var stop ProtectiveOrderTransaction
+	if err := txn.Decode(&stop); err != nil {
+		return err
+	}
+	trade, ok := opened[stop.TradeID]
+	if !ok || stop.Reason != "ON_FILL" || trade.risk != 0 {
+		return nil
+	}
+
+	if stop.Price != "" {
+		price, err := strconv.ParseFloat(stop.Price, 64)
+		if err != nil {
+			return fmt.Errorf("invalid stop price %q: %w", stop.Price, err)
+		}
+		trade.risk = math.Abs(trade.price - price)
+		return nil
+	}
+	distance, err := parseOptionalFloat(stop.Distance)
+	if err != nil {
+		return fmt.Errorf("invalid stop distance %q: %w", stop.Distance, err)
+	}
+	trade.risk = distance
+	return nil
+}
+
+func openTrade(fill *OrderFillTransaction, t time.Time) (*openedTrade, error) {
+	units, err := strconv.ParseFloat(fill.TradeOpened.Units, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid opened units %q: %w", fill.TradeOpened.Units, err)
+	}
+	rawPrice := fill.TradeOpened.Price
+	if rawPrice == "" {
+		rawPrice = fill.Price
+	}
+	price, err := strconv.ParseFloat(rawPrice, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid entry price %q: %w", rawPrice, err)
+	}
+	return &openedTrade{instrument: fill.Instrument, time: t, price: price, remaining: units}, nil
+}
+
+func (o *openedTrade) close(reduce TradeReduce, fillPrice string, t time.Time) (*JournalEntry, error) {
+	units, err := strconv.ParseFloat(reduce.Units, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid closed units %q: %w", reduce.Units, err)
+	}
+	rawPrice := reduce.Price
+	if rawPrice == "" {
+		rawPrice = fillPrice
+	}
+	price, err := strconv.ParseFloat(rawPrice, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exit price %q: %w", rawPrice, err)
+	}
+
+	entry := JournalEntry{
+		Instrument:    o.instrument,
+		EntryTime:     o.time,
+		EntryPrice:    o.price,
+		ExitTime:      t,
+		ExitPrice:     price,
+		Units:         -units,
+		HoldingPeriod: t.Sub(o.time),
+	}
+	if entry.PL, err = parseOptionalFloat(reduce.RealizedPL); err != nil {
+		return nil, fmt.Errorf("invalid realized P/L %q: %w", reduce.RealizedPL, err)
+	}
+	if entry.Financing, err = parseOptionalFloat(reduce.Financing); err != nil {
+		return nil, fmt.Errorf("invalid financing %q: %w", reduce.Financing, err)
+	}
+
+	if o.risk > 0 {
+		move := price - o.price
+		if entry.Units < 0 {
+			move = -move
+		}
+		r := move / o.risk
+		entry.RMultiple = &r
+	}
+
+	// Closing units have the opposite sign to the trade.
+	o.remaining += units
+	entry.Partial = o.remaining != 0
+	return &entry, nil
+}
+
+func parseOptionalFloat(s string) (float64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(s, 64)
+}
@@ -0,0 +1,95 @@
+package trader
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+const standardLotUnits = 100000
+
+// Contract sizes for instruments whose standard lot isn't 100,000 units, as
+// quoted by MT4/MT5 brokers. Anything else that isn't a currency pair, such
+// as an index CFD, trades one unit per lot.
+var lotSizes = map[string]float64{
+	"XAU": 100,
+	"XAG": 5000,
+	"XPT": 100,
+	"XPD": 100,
+}
+
+// LotSize returns the units in one standard lot of instrument. A mini lot is
+// a tenth of this and a micro lot a hundredth.
+func LotSize(instrument string) float64 {
+	base, quote, _ := strings.Cut(instrument, "_")
+	if size, ok := lotSizes[base]; ok {
+		return size
+	}
+	if isCurrencyCode(base) && isCurrencyCode(quote) {
+		return standardLotUnits
+	}
+	return 1
+}
+
+// LotsToUnits converts standard lots to units, rounding to the nearest
+// whole unit. Negative lots give negative (short) units.
+func LotsToUnits(lots float64, instrument string) int {
+	return int(math.Round(lots * LotSize(instrument)))
+}
+
+func UnitsToLots(units int, instrument string) float64 {
+	return float64(units) / LotSize(instrument)
+}
+
+// placeMarketOrderLots is placeMarketOrder with the size given in standard
+// lots. The converted size must meet the instrument's minimum trade size.
+func (c *Client) placeMarketOrderLots(lots float64, instrumentName string, priceBound float32) (*OrderResponse, error) {
+	units := LotsToUnits(lots, instrumentName)
+	instrument, err := c.instrument(instrumentName)
+	if err != nil {
+		return nil, err
+	}
+	if units == 0 || math.Abs(float64(units)) < instrument.MinimumTradeSize {
+		return nil, fmt.Errorf("%g lots of %s is %d units, below the minimum trade size of %g",
+			lots, instrumentName, units, instrument.MinimumTradeSize)
+	}
+	return c.placeMarketOrder(units, instrumentName, priceBound)
+}
+
+// placeMarketOrderSized is placeMarketOrder for a size straight out of
+// position sizing math, which is rarely a whole number of units. The size
+// goes through RoundUnits first, so it is floored to one OANDA accepts, and
+// an order that rounds to nothing or below the minimum trade size fails
+// without being sent.
+func (c *Client) placeMarketOrderSized(units float64, instrumentName string, priceBound float32) (*OrderResponse, error) {
+	rounded, err := c.RoundUnits(instrumentName, units)
+	if err != nil {
+		return nil, err
+	}
+	return c.placeMarketOrder(rounded, instrumentName, priceBound)
+}
+
+// RoundUnits rounds a computed position size down to a size OANDA will
+// accept for instrument. Sizes are floored towards zero rather than rounded
+// so sizing never takes on more risk than asked for. It fails if nothing is
+// left after rounding or the result is below the instrument's minimum trade
+// size.
+func (c *Client) RoundUnits(instrumentName string, units float64) (int, error) {
+	instrument, err := c.instrument(instrumentName)
+	if err != nil {
+		return 0, err
+	}
+
+	// Orders are placed in whole units, so a fractional increment still
+	// rounds to one unit.
+	increment := math.Max(1, math.Pow10(-instrument.TradeUnitsPrecision))
+	rounded := int(math.Trunc(units/increment) * increment)
+	if rounded == 0 {
+		return 0, fmt.Errorf("%g units of %s rounds to zero in increments of %g", units, instrumentName, increment)
+	}
+	if math.Abs(float64(rounded)) < instrument.MinimumTradeSize {
+		return 0, fmt.Errorf("%g units of %s rounds to %d, below the minimum trade size of %g",
+			units, instrumentName, rounded, instrument.MinimumTradeSize)
+	}
+	return rounded, nil
+}
@@ -0,0 +1,78 @@
+package trader
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// minimumSizeDoer serves EUR_USD with a minimum trade size of 100 units and
+// records the units of every order sent.
+func minimumSizeDoer(sent *[]string) Doer {
+	orders := &fakeDoer{}
+	return doerFunc(func(req *http.Request) (*http.Response, error) {
+		switch {
+		case strings.HasSuffix(req.URL.Path, "/instruments"):
+			return jsonResponse(200, `{"instruments": [{"name": "EUR_USD", "pipLocation": -4, "displayPrecision": 5,
+				"tradeUnitsPrecision": 0, "minimumTradeSize": "100", "marginRate": "0.02"}]}`), nil
+		case strings.HasSuffix(req.URL.Path, "/orders") && req.Method == "POST":
+			var body MarketOrderRequest
+			if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+				return nil, err
+			}
+			*sent = append(*sent, body.Order.Units)
+		}
+		return orders.Do(req)
+	})
+}
+
+func TestRoundUnits(t *testing.T) {
+	tests := []struct {
+		name    string
+		units   float64
+		want    int
+		wantErr bool
+	}{
+		{name: "long floored", units: 1234.9, want: 1234},
+		{name: "short floored towards zero", units: -1234.9, want: -1234},
+		{name: "rounds to zero", units: 0.6, wantErr: true},
+		{name: "below minimum trade size", units: 99.9, wantErr: true},
+		{name: "short below minimum trade size", units: -50, wantErr: true},
+	}
+
+	var sent []string
+	c := newTestClient(minimumSizeDoer(&sent))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.RoundUnits("EUR_USD", tt.units)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("RoundUnits(%g) = %d, want an error", tt.units, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("RoundUnits(%g) = %d, want %d", tt.units, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlaceMarketOrderSized(t *testing.T) {
+	var sent []string
+	c := newTestClient(minimumSizeDoer(&sent))
+
+	if _, err := c.placeMarketOrderSized(150.7, "EUR_USD", NoPriceBound); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.placeMarketOrderSized(99.9, "EUR_USD", NoPriceBound); err == nil {
+		t.Error("order below the minimum trade size was accepted")
+	}
+	if len(sent) != 1 || sent[0] != "150" {
+		t.Errorf("sent orders for %v units, want only [150]", sent)
+	}
+}
@@ -0,0 +1,42 @@
+package trader
+
+// MarginRate returns the margin rate OANDA applies to instrument on this
+// account, as a fraction of the position's value. An account can be set to
+// lower leverage than an instrument allows, in which case its own margin
+// rate overrides the instrument's, so the larger of the two is used. The
+// account's rate is fetched with getAccountSummary the first time and
+// cached, and refreshed whenever the account or its summary is fetched.
+func (c *Client) MarginRate(instrumentName string) (float64, error) {
+	instrument, err := c.instrument(instrumentName)
+	if err != nil {
+		return 0, err
+	}
+
+	c.mu.Lock()
+	accountRate := c.accountMarginRate
+	c.mu.Unlock()
+	if accountRate == 0 {
+		summaryResponse, err := c.getAccountSummary()
+		if err != nil {
+			return 0, err
+		}
+		accountRate = summaryResponse.Account.MarginRate
+	}
+	return max(instrument.MarginRate, accountRate), nil
+}
+
+// MarginRequired estimates the margin an order of units in instrument would
+// use, in the account's home currency: its OrderNotional times its
+// MarginRate. OANDA charges margin on the net position, so an order that
+// reduces a position uses less than this, or frees margin.
+func (c *Client) MarginRequired(instrument string, units int) (float64, error) {
+	rate, err := c.MarginRate(instrument)
+	if err != nil {
+		return 0, err
+	}
+	notional, err := c.OrderNotional(instrument, units)
+	if err != nil {
+		return 0, err
+	}
+	return notional * rate, nil
+}
@@ -0,0 +1,43 @@
+package trader
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type doerFunc func(req *http.Request) (*http.Response, error)
+
+func (f doerFunc) Do(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestMarginRate(t *testing.T) {
+	tests := []struct {
+		name        string
+		accountRate string
+		want        float64
+	}{
+		{name: "account override present", accountRate: "0.05", want: 0.05},
+		{name: "no override", accountRate: "0.01", want: 0.02},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instruments := &fakeDoer{}
+			c := newTestClient(doerFunc(func(req *http.Request) (*http.Response, error) {
+				if strings.HasSuffix(req.URL.Path, "/summary") {
+					return jsonResponse(200, fmt.Sprintf(`{"account": {"currency": "USD", "marginRate": %q}}`, tt.accountRate)), nil
+				}
+				return instruments.Do(req)
+			}))
+
+			got, err := c.MarginRate("EUR_USD")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("MarginRate = %g, want %g", got, tt.want)
+			}
+		})
+	}
+}
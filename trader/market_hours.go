@@ -0,0 +1,79 @@
+package trader
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+)
+
+// RunDuringMarketHours runs strat whenever instrument's market is open,
+// following the session table in trading_hours.go since OANDA's instrument
+// metadata carries no trading hours. While the market is closed nothing
+// runs and no requests are made. At each session's close strat's context is
+// cancelled, and it is started again at the next open. strat returning
+// early ends its session; an error it returns while the market is open
+// stops RunDuringMarketHours and is returned. It runs until ctx is
+// cancelled.
+func (c *Client) RunDuringMarketHours(ctx context.Context, instrument string, strat func(ctx context.Context) error) error {
+	for {
+		now := c.clock.Now()
+		if !IsOpen(instrument, now) {
+			next := NextOpen(instrument, now)
+			log.Printf("Market for %s is closed, pausing until %s", instrument, next)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-c.clock.After(next.Sub(now)):
+			}
+			continue
+		}
+
+		end := SessionEnd(instrument, now)
+		log.Printf("Market for %s is open, running until %s", instrument, end)
+		if err := c.runSession(ctx, end, strat); err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// strat may return before the close; wait it out so it isn't
+		// restarted within the same session.
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-c.clock.After(end.Sub(c.clock.Now())):
+		}
+	}
+}
+
+// runSession runs strat with a context that is cancelled at end. Errors
+// caused by that cancellation are not returned.
+func (c *Client) runSession(ctx context.Context, end time.Time, strat func(ctx context.Context) error) error {
+	sessionCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	closed := make(chan struct{})
+	go func() {
+		select {
+		case <-sessionCtx.Done():
+		case <-c.clock.After(end.Sub(c.clock.Now())):
+			close(closed)
+			cancel()
+		}
+	}()
+
+	err := strat(sessionCtx)
+	select {
+	case <-closed:
+		if errors.Is(err, context.Canceled) {
+			return nil
+		}
+	default:
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
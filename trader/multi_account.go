@@ -0,0 +1,100 @@
+package trader
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// MultiAccountClient fans requests out over several accounts, each with its
+// own Client and so its own credentials, and merges the results.
+type MultiAccountClient struct {
+	clients map[string]*Client
+}
+
+// AccountInstrument keys merged results by account and instrument.
+type AccountInstrument struct {
+	AccountID  string
+	Instrument string
+}
+
+func NewMultiAccountClient(clients ...*Client) (*MultiAccountClient, error) {
+	m := &MultiAccountClient{clients: make(map[string]*Client, len(clients))}
+	for _, c := range clients {
+		accountID := c.creds.AccountID
+		if _, ok := m.clients[accountID]; ok {
+			return nil, fmt.Errorf("account %s added more than once", accountID)
+		}
+		m.clients[accountID] = c
+	}
+	return m, nil
+}
+
+// Client returns the Client for accountID, or nil if it isn't part of m.
+func (m *MultiAccountClient) Client(accountID string) *Client {
+	return m.clients[accountID]
+}
+
+// forEach calls fn for every account concurrently. Failures are joined into
+// one error naming each failed account; fn's results for the other accounts
+// are kept.
+func (m *MultiAccountClient) forEach(fn func(accountID string, c *Client) error) error {
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	for accountID, c := range m.clients {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := fn(accountID, c); err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("account %s: %w", accountID, err))
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// getPrices fetches instruments through every account, keyed by account ID.
+// Accounts can see different prices, for example with different pricing
+// tiers, so the results are not merged further.
+func (m *MultiAccountClient) getPrices(instruments []string, opts PricingOptions) (map[string]*PricingResponse, error) {
+	var mu sync.Mutex
+	prices := make(map[string]*PricingResponse, len(m.clients))
+	err := m.forEach(func(accountID string, c *Client) error {
+		pricesResponse, err := c.getPrices(instruments, opts)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		prices[accountID] = pricesResponse
+		mu.Unlock()
+		return nil
+	})
+	return prices, err
+}
+
+// getOpenPositions returns the open positions of every account. When some
+// accounts fail the positions of the rest are still returned with the
+// error.
+func (m *MultiAccountClient) getOpenPositions() (map[AccountInstrument]Position, error) {
+	var mu sync.Mutex
+	merged := make(map[AccountInstrument]Position)
+	err := m.forEach(func(accountID string, c *Client) error {
+		positions, err := c.getOpenPositions()
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		for _, position := range positions {
+			merged[AccountInstrument{AccountID: accountID, Instrument: position.Instrument}] = position
+		}
+		mu.Unlock()
+		return nil
+	})
+	return merged, err
+}
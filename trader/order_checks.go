@@ -0,0 +1,216 @@
+package trader
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"time"
+)
+
+var (
+	ErrOrderTooLarge = errors.New("order units exceed the configured maximum")
+	ErrSpreadTooWide = errors.New("spread exceeds the configured maximum")
+	ErrPositionLimit = errors.New("order would take the position beyond the configured maximum")
+
+	ErrInstrumentNotTradeable = errors.New("instrument is not tradeable")
+	ErrQuoteStale             = errors.New("quote is older than the configured maximum age")
+)
+
+// SpreadError is returned when an order is refused because the current
+// spread is wider than allowed. It matches ErrSpreadTooWide with errors.Is.
+type SpreadError struct {
+	Instrument string
+	SpreadPips float64
+	MaxPips    float64
+}
+
+func (e *SpreadError) Error() string {
+	return fmt.Sprintf("%v: %s spread is %.1f pips, maximum is %.1f", ErrSpreadTooWide, e.Instrument, e.SpreadPips, e.MaxPips)
+}
+
+func (e *SpreadError) Unwrap() error {
+	return ErrSpreadTooWide
+}
+
+// WithMaxOrderUnits rejects any order whose absolute units exceed n before
+// it is sent. There is no cap by default.
+func WithMaxOrderUnits(n int) Option {
+	return func(c *Client) {
+		c.maxOrderUnits = n
+	}
+}
+
+// WithMaxPositionUnits rejects an order with ErrPositionLimit if it would
+// take the net position in its instrument beyond the given number of units
+// either way. The position is fetched before each order in a listed
+// instrument. Orders that shrink the position are always allowed, even
+// when it is already over the limit. Instruments not in maxUnits are not
+// checked. The map is copied, so later changes to it have no effect.
+func WithMaxPositionUnits(maxUnits map[string]int) Option {
+	return func(c *Client) {
+		c.maxPositionUnits = maps.Clone(maxUnits)
+	}
+}
+
+// WithMaxSpreadPips refuses orders in an instrument while its spread, taken
+// from a quote just before sending, is wider than the given number of pips.
+// Instruments not in maxPips are not checked. The map is copied, so later
+// changes to it have no effect.
+func WithMaxSpreadPips(maxPips map[string]float64) Option {
+	return func(c *Client) {
+		c.maxSpreadPips = maps.Clone(maxPips)
+	}
+}
+
+// WithMaxQuoteAge refuses to act on a quote whose time is more than maxAge
+// before now, returning ErrQuoteStale. It applies wherever the Client
+// derives an order from a quote, including the pre-trade checks and
+// placeMarketOrderWithTolerance, and catches cached and fallback prices as
+// well as a frozen feed. A quote's time is that of the last price update,
+// so an instrument that is quiet or closed also reads as stale.
+func WithMaxQuoteAge(maxAge time.Duration) Option {
+	return func(c *Client) {
+		c.maxQuoteAge = maxAge
+	}
+}
+
+// WithPreTradeCheck runs check on every order before it is sent, aborting
+// the order with check's error if it returns one. It sees market orders as
+// submitted and other orders, such as trailing stops, converted to a
+// MarketOrder carrying their type. Closing trades and positions is never
+// blocked. Checks from repeated options run in the order given. Checks run
+// while the order's instrument is locked, so they must not place orders in
+// that instrument themselves.
+func WithPreTradeCheck(check func(order MarketOrder) error) Option {
+	return func(c *Client) {
+		c.preTradeChecks = append(c.preTradeChecks, check)
+	}
+}
+
+func (c *Client) runPreTradeChecks(order MarketOrder) error {
+	for _, check := range c.preTradeChecks {
+		if err := check(order); err != nil {
+			return fmt.Errorf("pre-trade check refused %s order in %s: %w", order.Type, order.Instrument, err)
+		}
+	}
+	return nil
+}
+
+// WithoutTradeableCheck stops the Client quoting an instrument before each
+// order to confirm it is tradeable, for callers that check themselves and
+// want to save the round trip. OANDA still rejects orders in halted
+// instruments.
+func WithoutTradeableCheck() Option {
+	return func(c *Client) {
+		c.skipTradeableCheck = true
+	}
+}
+
+// checkOrder runs the Client's pre-submission guards against an order. The
+// checks that need a quote share a single one, which comes from the price
+// cache when WithPriceCache is set.
+func (c *Client) checkOrder(order MarketOrder, units int) error {
+	if err := c.checkOrderGuards(order, units); err != nil {
+		return err
+	}
+	if maxUnits, ok := c.maxPositionUnits[order.Instrument]; ok {
+		if err := c.checkPositionLimit(order.Instrument, units, maxUnits); err != nil {
+			return err
+		}
+	}
+
+	maxPips, checkSpread := c.maxSpreadPips[order.Instrument]
+	if c.skipTradeableCheck && !checkSpread {
+		return nil
+	}
+	quote, err := c.quote(order.Instrument)
+	if err != nil {
+		return fmt.Errorf("fetching quote for pre-trade checks: %w", err)
+	}
+
+	if !c.skipTradeableCheck && !quote.Tradeable {
+		return fmt.Errorf("%w: %s", ErrInstrumentNotTradeable, order.Instrument)
+	}
+	if checkSpread {
+		if err := c.checkSpread(quote, maxPips); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkOrderGuards runs the checks every order must pass, whatever its
+// type: the halt, the instrument allow and deny lists, the pre-trade checks
+// and the order size cap. An order with no instrument of its own, such as a
+// stop loss on a trade, skips the allow and deny lists.
+func (c *Client) checkOrderGuards(order MarketOrder, units int) error {
+	if err := c.checkHalted(); err != nil {
+		return err
+	}
+	if order.Instrument != "" {
+		if err := c.checkInstrumentAllowed(order.Instrument); err != nil {
+			return err
+		}
+	}
+	if err := c.runPreTradeChecks(order); err != nil {
+		return err
+	}
+	if c.maxOrderUnits > 0 && abs(units) > c.maxOrderUnits {
+		return fmt.Errorf("%w: %d units of %s, maximum is %d", ErrOrderTooLarge, units, order.Instrument, c.maxOrderUnits)
+	}
+	return nil
+}
+
+func (c *Client) checkPositionLimit(instrument string, units, maxUnits int) error {
+	position, err := c.getPosition(instrument)
+	if err != nil {
+		return fmt.Errorf("fetching position for pre-trade checks: %w", err)
+	}
+
+	net := int(position.NetUnits)
+	after := net + units
+	if abs(after) > maxUnits && abs(after) > abs(net) {
+		return fmt.Errorf("%w: %d units of %s would make the position %d, maximum is %d",
+			ErrPositionLimit, units, instrument, after, maxUnits)
+	}
+	return nil
+}
+
+func (c *Client) checkSpread(quote *Price, maxPips float64) error {
+	instrumentName := quote.Instrument
+	instrument, err := c.instrument(instrumentName)
+	if err != nil {
+		return err
+	}
+
+	spreadPips := float64(quote.Ask-quote.Bid) / instrument.PipSize()
+	if spreadPips > maxPips {
+		return &SpreadError{Instrument: instrumentName, SpreadPips: spreadPips, MaxPips: maxPips}
+	}
+	return nil
+}
+
+// quote returns the current price of a single instrument.
+func (c *Client) quote(instrument string) (*Price, error) {
+	pricesResponse, err := c.getPrices([]string{instrument}, PricingOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, price := range pricesResponse.Prices {
+		if price.Instrument != instrument {
+			continue
+		}
+		if age := c.clock.Now().Sub(price.Time); c.maxQuoteAge > 0 && age > c.maxQuoteAge {
+			return nil, fmt.Errorf("%w: %s quote is %s old, maximum is %s", ErrQuoteStale, instrument, age.Round(time.Millisecond), c.maxQuoteAge)
+		}
+		return &price, nil
+	}
+	return nil, fmt.Errorf("no price received for %s", instrument)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,353 @@
+package trader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	orderSpecifierEndpoint = "/v3/accounts/{accountID}/orders/{orderSpecifier}"
+	cancelOrderEndpoint    = "/v3/accounts/{accountID}/orders/{orderSpecifier}/cancel"
+	pendingOrdersEndpoint  = "/v3/accounts/{accountID}/pendingOrders"
+)
+
+const keepAlivePollInterval = 30 * time.Second
+
+type OrdersResponse struct {
+	Orders            []Order `json:"orders"`
+	LastTransactionID string  `json:"lastTransactionID"`
+}
+
+type CancelOrderResponse struct {
+	OrderCancelTransaction OrderCancelTransaction `json:"orderCancelTransaction"`
+	RelatedTransactionIDs  []string               `json:"relatedTransactionIDs"`
+	LastTransactionID      string                 `json:"lastTransactionID"`
+}
+
+// OrderCancelResult is the outcome of cancelling one order. Err is set when
+// the cancel failed, otherwise Transaction holds OANDA's record of it.
+type OrderCancelResult struct {
+	OrderID     string
+	Transaction *OrderCancelTransaction
+	Err         error
+}
+
+type OrderDetailResponse struct {
+	Order             Order  `json:"order"`
+	LastTransactionID string `json:"lastTransactionID"`
+}
+
+// Order is a pending or historical order as returned by the orders
+// endpoints. OrderSpec holds the same fields for submitting one.
+type Order struct {
+	ID               string            `json:"id"`
+	CreateTime       string            `json:"createTime"`
+	State            string            `json:"state"`
+	Type             string            `json:"type"`
+	Instrument       string            `json:"instrument"`
+	Units            string            `json:"units"`
+	Price            string            `json:"price"`
+	PriceBound       string            `json:"priceBound"`
+	TradeID          string            `json:"tradeID"`
+	Distance         string            `json:"distance"`
+	TimeInForce      string            `json:"timeInForce"`
+	GtdTime          string            `json:"gtdTime"`
+	PositionFill     string            `json:"positionFill"`
+	TriggerCondition string            `json:"triggerCondition"`
+	ClientExtensions *ClientExtensions `json:"clientExtensions"`
+
+	FillingTransactionID    string `json:"fillingTransactionID"`
+	TradeOpenedID           string `json:"tradeOpenedID"`
+	TradeReducedID          string `json:"tradeReducedID"`
+	CancellingTransactionID string `json:"cancellingTransactionID"`
+}
+
+type OrderSpec struct {
+	Type             string            `json:"type"`
+	Instrument       string            `json:"instrument,omitempty"`
+	Units            string            `json:"units,omitempty"`
+	Price            string            `json:"price,omitempty"`
+	PriceBound       string            `json:"priceBound,omitempty"`
+	TradeID          string            `json:"tradeID,omitempty"`
+	Distance         string            `json:"distance,omitempty"`
+	TimeInForce      string            `json:"timeInForce,omitempty"`
+	GtdTime          string            `json:"gtdTime,omitempty"`
+	PositionFill     string            `json:"positionFill,omitempty"`
+	TriggerCondition string            `json:"triggerCondition,omitempty"`
+	ClientExtensions *ClientExtensions `json:"clientExtensions,omitempty"`
+}
+
+func (o *Order) spec() OrderSpec {
+	return OrderSpec{
+		Type:             o.Type,
+		Instrument:       o.Instrument,
+		Units:            o.Units,
+		Price:            o.Price,
+		PriceBound:       o.PriceBound,
+		TradeID:          o.TradeID,
+		Distance:         o.Distance,
+		TimeInForce:      o.TimeInForce,
+		GtdTime:          o.GtdTime,
+		PositionFill:     o.PositionFill,
+		TriggerCondition: o.TriggerCondition,
+		ClientExtensions: o.ClientExtensions,
+	}
+}
+
+// marketOrder presents spec to pre-trade checks, which take a MarketOrder.
+func (s *OrderSpec) marketOrder(instrument string) MarketOrder {
+	return MarketOrder{
+		Units:            s.Units,
+		Instrument:       instrument,
+		PriceBound:       s.PriceBound,
+		TimeInForce:      s.TimeInForce,
+		Type:             s.Type,
+		PositionFill:     s.PositionFill,
+		ClientExtensions: s.ClientExtensions,
+	}
+}
+
+// checkOrderSpec runs the guards common to every order against spec. Specs
+// without units, such as protective orders, count as zero units.
+func (c *Client) checkOrderSpec(spec OrderSpec, instrument string) error {
+	var units int
+	if spec.Units != "" {
+		var err error
+		units, err = strconv.Atoi(spec.Units)
+		if err != nil {
+			return fmt.Errorf("invalid units %q: %w", spec.Units, err)
+		}
+	}
+	return c.checkOrderGuards(spec.marketOrder(instrument), units)
+}
+
+func orderPath(orderID string) string {
+	return strings.Replace(orderSpecifierEndpoint, "{orderSpecifier}", orderID, 1)
+}
+
+func (c *Client) getPendingOrders(ctx context.Context) ([]Order, error) {
+	var ordersResponse OrdersResponse
+	err := c.do(ctx, "GET", pendingOrdersEndpoint, nil, nil, 200, &ordersResponse)
+	if err != nil {
+		return nil, err
+	}
+	return ordersResponse.Orders, nil
+}
+
+func (c *Client) cancelOrder(ctx context.Context, orderID string) (*OrderCancelTransaction, error) {
+	var cancelResponse CancelOrderResponse
+	endpoint := strings.Replace(cancelOrderEndpoint, "{orderSpecifier}", orderID, 1)
+	err := c.do(ctx, "PUT", endpoint, nil, nil, 200, &cancelResponse)
+	if err != nil {
+		return nil, err
+	}
+	return &cancelResponse.OrderCancelTransaction, nil
+}
+
+// CancelTaggedOrders cancels every pending order whose client extension tag
+// is tag, so orders a bot left behind can't fill while it is offline. Call
+// it on the way out with a context that outlives the one being shut down,
+// for example:
+//
+//	<-ctx.Done()
+//	cleanup, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+//	defer cancel()
+//	results, err := c.CancelTaggedOrders(cleanup, "my-bot")
+//
+// One result is returned per matching order. The error joins every failed
+// cancel, or is the listing error when the pending orders couldn't be
+// fetched.
+func (c *Client) CancelTaggedOrders(ctx context.Context, tag string) ([]OrderCancelResult, error) {
+	if tag == "" {
+		return nil, fmt.Errorf("tag must not be empty")
+	}
+
+	orders, err := c.getPendingOrders(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []OrderCancelResult
+	var errs []error
+	for _, order := range orders {
+		if order.ClientExtensions == nil || order.ClientExtensions.Tag != tag {
+			continue
+		}
+
+		transaction, err := c.cancelOrder(ctx, order.ID)
+		if err != nil {
+			err = fmt.Errorf("cancelling order %s: %w", order.ID, err)
+			errs = append(errs, err)
+		}
+		results = append(results, OrderCancelResult{OrderID: order.ID, Transaction: transaction, Err: err})
+	}
+	return results, errors.Join(errs...)
+}
+
+func (c *Client) getOrder(orderID string) (*Order, error) {
+	var orderResponse OrderDetailResponse
+	err := c.do(context.Background(), "GET", orderPath(orderID), nil, nil, 200, &orderResponse)
+	if err != nil {
+		return nil, err
+	}
+	return &orderResponse.Order, nil
+}
+
+// submitOrderSpec places a non-market order such as a protective order on
+// an existing trade.
+func (c *Client) submitOrderSpec(spec OrderSpec, instrument string) (*OrderResponse, error) {
+	defer c.instrumentLocks.lock(instrument)()
+
+	if err := c.checkOrderSpec(spec, instrument); err != nil {
+		return nil, err
+	}
+
+	idempotencyKey, err := newIdempotencyKey()
+	if err != nil {
+		return nil, err
+	}
+
+	if spec.ClientExtensions == nil {
+		spec.ClientExtensions = &ClientExtensions{}
+	}
+	spec.ClientExtensions.ID = idempotencyKey
+
+	var orderResponse OrderResponse
+	body := map[string]OrderSpec{"order": spec}
+	release := c.acquireOrderSlot()
+	err = c.do(context.Background(), "POST", orderEndpoint, nil, body, 201, &orderResponse)
+	release()
+	if err != nil {
+		return nil, err
+	}
+
+	c.auditOrder(&orderResponse, instrument, 0, idempotencyKey)
+	return &orderResponse, nil
+}
+
+// placeTrailingStopLoss attaches a trailing stop loss to a trade, trailing
+// the price by distance in price units.
+func (c *Client) placeTrailingStopLoss(tradeID string, distance float64) (*OrderResponse, error) {
+	trade, err := c.getTrade(tradeID)
+	if err != nil {
+		return nil, err
+	}
+	instrument, err := c.instrument(trade.Instrument)
+	if err != nil {
+		return nil, err
+	}
+	return c.submitTrailingStopLoss(tradeID, instrument, distance)
+}
+
+// placeTrailingStopLossPips is placeTrailingStopLoss with the distance given
+// in pips of the trade's instrument, e.g. 15 pips is 0.0015 on EUR_USD and
+// 0.15 on USD_JPY.
+func (c *Client) placeTrailingStopLossPips(tradeID string, pips float64) (*OrderResponse, error) {
+	if pips <= 0 {
+		return nil, fmt.Errorf("trailing stop distance must be a positive number of pips, got %g", pips)
+	}
+
+	trade, err := c.getTrade(tradeID)
+	if err != nil {
+		return nil, err
+	}
+	instrument, err := c.instrument(trade.Instrument)
+	if err != nil {
+		return nil, err
+	}
+	return c.submitTrailingStopLoss(tradeID, instrument, pips*instrument.PipSize())
+}
+
+func (c *Client) submitTrailingStopLoss(tradeID string, instrument *Instrument, distance float64) (*OrderResponse, error) {
+	if distance <= 0 {
+		return nil, fmt.Errorf("trailing stop distance must be positive, got %g", distance)
+	}
+	if distance < instrument.MinimumTrailingStopDistance {
+		return nil, fmt.Errorf("trailing stop distance %g is below the %s minimum of %g",
+			distance, instrument.Name, instrument.MinimumTrailingStopDistance)
+	}
+	if instrument.MaximumTrailingStopDistance > 0 && distance > instrument.MaximumTrailingStopDistance {
+		return nil, fmt.Errorf("trailing stop distance %g is above the %s maximum of %g",
+			distance, instrument.Name, instrument.MaximumTrailingStopDistance)
+	}
+
+	return c.submitOrderSpec(OrderSpec{
+		Type:        "TRAILING_STOP_LOSS",
+		TradeID:     tradeID,
+		Distance:    instrument.formatPrice(distance),
+		TimeInForce: "GTC",
+	}, instrument.Name)
+}
+
+// replaceOrder cancels orderID and creates spec in its place. The
+// replacement has a new ID, found in the response's OrderCreateTransaction.
+// It holds the instrument's order lock while it runs, unless spec has no
+// instrument of its own, as with orders attached to a trade.
+func (c *Client) replaceOrder(orderID string, spec OrderSpec) (*OrderResponse, error) {
+	if spec.Instrument != "" {
+		defer c.instrumentLocks.lock(spec.Instrument)()
+	}
+
+	if err := c.checkOrderSpec(spec, spec.Instrument); err != nil {
+		return nil, err
+	}
+
+	var orderResponse OrderResponse
+	body := map[string]OrderSpec{"order": spec}
+	release := c.acquireOrderSlot()
+	err := c.do(context.Background(), "PUT", orderPath(orderID), nil, body, 201, &orderResponse)
+	release()
+	if err != nil {
+		return nil, err
+	}
+	return &orderResponse, nil
+}
+
+// KeepOrderAlive watches a pending GTD order and, whenever less than half of
+// extendBy remains before it expires, replaces it with a copy expiring
+// extendBy from now. Each replacement gets a new order ID; the latest one is
+// returned once the order stops pending (filled or cancelled) or ctx ends.
+func (c *Client) KeepOrderAlive(ctx context.Context, orderID string, extendBy time.Duration) (string, error) {
+	if extendBy < 2*keepAlivePollInterval {
+		return orderID, fmt.Errorf("extendBy must be at least %s, got %s", 2*keepAlivePollInterval, extendBy)
+	}
+
+	for {
+		order, err := c.getOrder(orderID)
+		if err != nil {
+			return orderID, err
+		}
+		if order.State != "PENDING" {
+			return orderID, nil
+		}
+		if order.TimeInForce != "GTD" {
+			return orderID, fmt.Errorf("order %s has time in force %s, not GTD", orderID, order.TimeInForce)
+		}
+
+		gtd, err := time.Parse(time.RFC3339Nano, order.GtdTime)
+		if err != nil {
+			return orderID, fmt.Errorf("invalid GTD time %q on order %s: %w", order.GtdTime, orderID, err)
+		}
+		if gtd.Sub(c.clock.Now()) < extendBy/2 {
+			spec := order.spec()
+			spec.GtdTime = c.clock.Now().Add(extendBy).UTC().Format(time.RFC3339)
+			replaceResponse, err := c.replaceOrder(orderID, spec)
+			if err != nil {
+				return orderID, err
+			}
+			log.Printf("Extended order %s to %s as order %s", orderID, spec.GtdTime, replaceResponse.OrderCreateTransaction.ID)
+			orderID = replaceResponse.OrderCreateTransaction.ID
+		}
+
+		select {
+		case <-ctx.Done():
+			return orderID, ctx.Err()
+		case <-c.clock.After(keepAlivePollInterval):
+		}
+	}
+}
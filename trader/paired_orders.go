@@ -0,0 +1,101 @@
+package trader
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// PairedOrderResult reports both legs of PlacePairedOrders. When the second
+// leg fails after the first has filled, Unwind holds the responses that
+// reversed the first leg and UnwindErr any error doing so.
+type PairedOrderResult struct {
+	A         *OrderResponse
+	B         *OrderResponse
+	Unwind    []*OrderResponse
+	UnwindErr error
+}
+
+// PlacePairedOrders submits a and then b. OANDA has no way to submit orders
+// as one transaction, so the legs are sequenced: if a doesn't fill nothing
+// else is sent, and if b errors or doesn't fill, a is unwound straight away
+// to avoid holding one leg. Unwinding closes the trade a opened and places
+// an unbounded opposing market order for any units a closed or reduced
+// instead, skipping the pre-trade checks so that a halt or a wide spread
+// can't leave the leg in place. There is a brief window where only a is
+// held.
+func (c *Client) PlacePairedOrders(a, b MarketOrder) (*PairedOrderResult, error) {
+	unitsA, err := strconv.Atoi(a.Units)
+	if err != nil {
+		return nil, fmt.Errorf("invalid units %q for first leg: %w", a.Units, err)
+	}
+	unitsB, err := strconv.Atoi(b.Units)
+	if err != nil {
+		return nil, fmt.Errorf("invalid units %q for second leg: %w", b.Units, err)
+	}
+
+	var result PairedOrderResult
+	result.A, err = c.submitMarketOrder(a, unitsA)
+	if err != nil {
+		return &result, fmt.Errorf("first leg %s failed: %w", a.Instrument, err)
+	}
+	if result.A.OrderFillTransaction.ID == "" {
+		return &result, fmt.Errorf("first leg %s was not filled", a.Instrument)
+	}
+
+	result.B, err = c.submitMarketOrder(b, unitsB)
+	if err == nil && result.B.OrderFillTransaction.ID == "" {
+		err = fmt.Errorf("not filled")
+	}
+	if err == nil {
+		return &result, nil
+	}
+
+	result.Unwind, result.UnwindErr = c.unwindFill(result.A)
+	if result.UnwindErr != nil {
+		return &result, fmt.Errorf("second leg %s failed (%v) and unwinding first leg %s also failed: %w", b.Instrument, err, a.Instrument, result.UnwindErr)
+	}
+	return &result, fmt.Errorf("second leg %s failed, first leg %s was unwound: %w", b.Instrument, a.Instrument, err)
+}
+
+// unwindFill reverses all of a fill: the trade it opened is closed, and the
+// units it took off existing trades are put back with an opposing market
+// order. Neither goes through the pre-trade checks.
+func (c *Client) unwindFill(orderResponse *OrderResponse) ([]*OrderResponse, error) {
+	fill := orderResponse.OrderFillTransaction
+	units, err := strconv.ParseFloat(fill.Units, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filled units %q: %w", fill.Units, err)
+	}
+
+	var unwound []*OrderResponse
+	if tradeID := fill.TradeOpened.TradeID; tradeID != "" {
+		opened, err := strconv.ParseFloat(fill.TradeOpened.Units, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid opened units %q: %w", fill.TradeOpened.Units, err)
+		}
+		closeResult, err := c.closeTrade(tradeID, "ALL", CloseTradeOptions{})
+		if err != nil {
+			return nil, err
+		}
+		unwound = append(unwound, closeResult.Close)
+		units -= opened
+	}
+
+	reduced := int(math.Round(-units))
+	if reduced == 0 {
+		return unwound, nil
+	}
+	defer c.instrumentLocks.lock(fill.Instrument)()
+	response, err := c.sendMarketOrder(MarketOrder{
+		Units:        strconv.Itoa(reduced),
+		Instrument:   fill.Instrument,
+		TimeInForce:  "FOK",
+		Type:         "MARKET",
+		PositionFill: "DEFAULT",
+	}, reduced)
+	if err != nil {
+		return unwound, fmt.Errorf("restoring %d units of %s: %w", reduced, fill.Instrument, err)
+	}
+	return append(unwound, response), nil
+}
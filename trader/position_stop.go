@@ -0,0 +1,64 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+)
+
+// Side is the direction of a position.
+type Side string
+
+const (
+	SideLong  Side = "long"
+	SideShort Side = "short"
+)
+
+// StopTrigger reports a PositionStopMonitor firing: the price that
+// breached the stop and the response to closing the position.
+type StopTrigger struct {
+	Price Price
+	Close *ClosePositionResponse
+}
+
+// PositionStopMonitor holds a stop on the whole position in instrument
+// rather than on individual trades. It streams prices until the closing
+// side of the market reaches stopPrice, the bid falling to it for a long
+// position or the ask rising to it for a short one, then closes the
+// position with closePosition and returns what happened.
+//
+// This is a software stop and only protects while the monitor runs and the
+// stream is connected. Nothing happens during a disconnect, a gap past the
+// stop is closed at the next price and not the stop, and the close is a
+// market order that can slip. If the stream ends the monitor returns an
+// error and must be restarted. On a hedging account both sides of the
+// position are closed. Returns ctx.Err() when cancelled before triggering.
+func (c *Client) PositionStopMonitor(ctx context.Context, instrument string, stopPrice float32, side Side) (*StopTrigger, error) {
+	if side != SideLong && side != SideShort {
+		return nil, fmt.Errorf("invalid position side %q", side)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	prices, err := c.StreamPrices(ctx, []string{instrument})
+	if err != nil {
+		return nil, err
+	}
+
+	for price := range prices {
+		breached := (side == SideLong && price.Bid <= stopPrice) || (side == SideShort && price.Ask >= stopPrice)
+		if !breached {
+			continue
+		}
+
+		closeResponse, err := c.closePosition(instrument)
+		if err != nil {
+			return &StopTrigger{Price: price}, fmt.Errorf("position stop on %s triggered at %g but closing failed: %w", instrument, stopPrice, err)
+		}
+		return &StopTrigger{Price: price, Close: closeResponse}, nil
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("pricing stream for %s ended, position stop at %g is no longer monitored", instrument, stopPrice)
+}
@@ -0,0 +1,235 @@
+package trader
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+)
+
+const (
+	openPositionsEndpoint = "/v3/accounts/{accountID}/openPositions"
+	positionEndpoint      = "/v3/accounts/{accountID}/positions/{instrument}"
+	closePositionEndpoint = "/v3/accounts/{accountID}/positions/{instrument}/close"
+)
+
+type PositionResponse struct {
+	Position          Position `json:"position"`
+	LastTransactionID string   `json:"lastTransactionID"`
+}
+
+type PositionsResponse struct {
+	Positions         []Position `json:"positions"`
+	LastTransactionID string     `json:"lastTransactionID"`
+}
+
+// Position is everything held in one instrument. OANDA reports the two sides
+// separately, with Short.Units negative, so the totals are also given
+// normalised: LongUnits and ShortUnits are both zero or positive and
+// NetUnits is LongUnits minus ShortUnits, positive when net long. A netting
+// account only ever has one side open so NetUnits is the whole position; a
+// hedging account can hold both, and NetUnits is then the exposure left
+// after they offset.
+type Position struct {
+	Instrument   string       `json:"instrument"`
+	PL           float64      `json:"pl,string"`
+	UnrealizedPL float64      `json:"unrealizedPL,string"`
+	Long         PositionSide `json:"long"`
+	Short        PositionSide `json:"short"`
+
+	NetUnits   float64 `json:"-"`
+	LongUnits  float64 `json:"-"`
+	ShortUnits float64 `json:"-"`
+}
+
+type PositionSide struct {
+	Units        float64  `json:"units,string"`
+	AveragePrice float64  `json:"averagePrice,string"`
+	TradeIDs     []string `json:"tradeIDs"`
+	PL           float64  `json:"pl,string"`
+	UnrealizedPL float64  `json:"unrealizedPL,string"`
+}
+
+type ClosePositionRequest struct {
+	LongUnits  string `json:"longUnits,omitempty"`
+	ShortUnits string `json:"shortUnits,omitempty"`
+}
+
+type ClosePositionResponse struct {
+	LongOrderCreateTransaction  *OrderCreateTransaction `json:"longOrderCreateTransaction"`
+	LongOrderFillTransaction    *OrderFillTransaction   `json:"longOrderFillTransaction"`
+	ShortOrderCreateTransaction *OrderCreateTransaction `json:"shortOrderCreateTransaction"`
+	ShortOrderFillTransaction   *OrderFillTransaction   `json:"shortOrderFillTransaction"`
+	RelatedTransactionIDs       []string                `json:"relatedTransactionIDs"`
+	LastTransactionID           string                  `json:"lastTransactionID"`
+}
+
+func (p *Position) UnmarshalJSON(data []byte) error {
+	type plain Position
+	if err := json.Unmarshal(data, (*plain)(p)); err != nil {
+		return err
+	}
+
+	p.LongUnits = math.Abs(p.Long.Units)
+	p.ShortUnits = math.Abs(p.Short.Units)
+	p.NetUnits = p.LongUnits - p.ShortUnits
+	return nil
+}
+
+func (c *Client) getOpenPositions() ([]Position, error) {
+	var positionsResponse PositionsResponse
+	err := c.do(context.Background(), "GET", openPositionsEndpoint, nil, nil, 200, &positionsResponse)
+	if err != nil {
+		return nil, err
+	}
+	return positionsResponse.Positions, nil
+}
+
+// GetOpenPositions returns every position with open units.
+func (c *Client) GetOpenPositions() ([]Position, error) {
+	return c.getOpenPositions()
+}
+
+func (c *Client) getPosition(instrument string) (*Position, error) {
+	var positionResponse PositionResponse
+	endpoint := strings.Replace(positionEndpoint, "{instrument}", instrument, 1)
+	err := c.do(context.Background(), "GET", endpoint, nil, nil, 200, &positionResponse)
+	if err != nil {
+		return nil, err
+	}
+	return &positionResponse.Position, nil
+}
+
+// closePosition closes out everything held in instrument. A netting account
+// only ever holds one side, while a hedging account may hold long and short
+// units at once, in which case both sides are closed.
+func (c *Client) closePosition(instrument string) (*ClosePositionResponse, error) {
+	mode, err := c.PositionMode()
+	if err != nil {
+		return nil, err
+	}
+	position, err := c.getPosition(instrument)
+	if err != nil {
+		return nil, err
+	}
+
+	var closeRequest ClosePositionRequest
+	if position.Long.Units != 0 {
+		closeRequest.LongUnits = "ALL"
+	}
+	if position.Short.Units != 0 {
+		closeRequest.ShortUnits = "ALL"
+	}
+	if closeRequest.LongUnits == "" && closeRequest.ShortUnits == "" {
+		return nil, fmt.Errorf("no open position in %s to close", instrument)
+	}
+	if mode == NettingMode && closeRequest.LongUnits != "" && closeRequest.ShortUnits != "" {
+		return nil, fmt.Errorf("netting account reports both long and short units in %s: %w", instrument, ErrInvalidForPositionMode)
+	}
+
+	var closeResponse ClosePositionResponse
+	endpoint := strings.Replace(closePositionEndpoint, "{instrument}", instrument, 1)
+	err = c.do(context.Background(), "PUT", endpoint, nil, closeRequest, 200, &closeResponse)
+	if err != nil {
+		return nil, err
+	}
+	return &closeResponse, nil
+}
+
+// placeReduceOnlyOrder places a market order that may only shrink the
+// existing position. Hedging accounts hold each trade independently, so a
+// reduce-only order is rejected there; close the trade or position instead.
+func (c *Client) placeReduceOnlyOrder(units int, instrument string, priceBound float32) (*OrderResponse, error) {
+	if err := c.requirePositionMode(NettingMode, "reduce-only order"); err != nil {
+		return nil, err
+	}
+
+	return c.submitMarketOrder(MarketOrder{
+		Units:        fmt.Sprintf("%d", units),
+		Instrument:   instrument,
+		PriceBound:   c.formatPriceBound(instrument, units, priceBound),
+		TimeInForce:  "FOK",
+		Type:         "MARKET",
+		PositionFill: "REDUCE_ONLY",
+	}, units)
+}
+
+// AverageEntry returns the units-weighted average fill price of the open
+// trades in instrument, which is where a stop should be measured from when a
+// position was built up from several fills. Long and short trades are
+// averaged separately; a hedging account holding both sides at once has no
+// single entry, so that is an error, as is having no open trades. Use
+// AverageEntryForSide for one side of a hedged position.
+func (c *Client) AverageEntry(instrument string) (float64, error) {
+	long, short, err := c.openEntries(instrument)
+	if err != nil {
+		return 0, err
+	}
+
+	switch {
+	case long.units == 0 && short.units == 0:
+		return 0, fmt.Errorf("no open position in %s", instrument)
+	case long.units != 0 && short.units != 0:
+		return 0, fmt.Errorf("both long and short trades are open in %s, so there is no single average entry", instrument)
+	case long.units != 0:
+		return long.average(), nil
+	default:
+		return short.average(), nil
+	}
+}
+
+// AverageEntryForSide is AverageEntry for the trades on one side of
+// instrument only, so each side of a hedged position has its own entry.
+// Having no open trades on that side is an error.
+func (c *Client) AverageEntryForSide(instrument string, side Side) (float64, error) {
+	if side != SideLong && side != SideShort {
+		return 0, fmt.Errorf("invalid position side %q", side)
+	}
+	long, short, err := c.openEntries(instrument)
+	if err != nil {
+		return 0, err
+	}
+
+	entry := long
+	if side == SideShort {
+		entry = short
+	}
+	if entry.units == 0 {
+		return 0, fmt.Errorf("no open %s position in %s", side, instrument)
+	}
+	return entry.average(), nil
+}
+
+// entryTotals sums the absolute units and units-weighted cost of trades.
+type entryTotals struct {
+	units, cost float64
+}
+
+func (e entryTotals) average() float64 {
+	return e.cost / e.units
+}
+
+// openEntries totals the open long and short trades in instrument, matching
+// trades by normalised instrument name.
+func (c *Client) openEntries(instrument string) (long, short entryTotals, err error) {
+	trades, err := c.getOpenTrades()
+	if err != nil {
+		return long, short, err
+	}
+
+	name := normalizeInstrument(instrument)
+	for _, trade := range trades {
+		if normalizeInstrument(trade.Instrument) != name {
+			continue
+		}
+		if trade.CurrentUnits > 0 {
+			long.units += trade.CurrentUnits
+			long.cost += trade.CurrentUnits * trade.Price
+		} else {
+			short.units -= trade.CurrentUnits
+			short.cost -= trade.CurrentUnits * trade.Price
+		}
+	}
+	return long, short, nil
+}
@@ -0,0 +1,66 @@
+package trader
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestPositionUnitsNormalised(t *testing.T) {
+	tests := []struct {
+		name             string
+		json             string
+		net, long, short float64
+	}{
+		{
+			name: "netting long",
+			json: `{"instrument": "EUR_USD", "long": {"units": "1000"}, "short": {"units": "0"}}`,
+			net:  1000, long: 1000, short: 0,
+		},
+		{
+			name: "netting short",
+			json: `{"instrument": "EUR_USD", "long": {"units": "0"}, "short": {"units": "-250"}}`,
+			net:  -250, long: 0, short: 250,
+		},
+		{
+			name: "hedging both sides open",
+			json: `{"instrument": "EUR_USD", "long": {"units": "300", "tradeIDs": ["1"]}, "short": {"units": "-500", "tradeIDs": ["2"]}}`,
+			net:  -200, long: 300, short: 500,
+		},
+		{
+			name: "hedging fully offset",
+			json: `{"instrument": "EUR_USD", "long": {"units": "400"}, "short": {"units": "-400"}}`,
+			net:  0, long: 400, short: 400,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var position Position
+			if err := json.Unmarshal([]byte(tt.json), &position); err != nil {
+				t.Fatal(err)
+			}
+			if position.NetUnits != tt.net || position.LongUnits != tt.long || position.ShortUnits != tt.short {
+				t.Errorf("got net %g, long %g, short %g; want %g, %g, %g",
+					position.NetUnits, position.LongUnits, position.ShortUnits, tt.net, tt.long, tt.short)
+			}
+		})
+	}
+}
+
+func TestPositionsResponseNormalised(t *testing.T) {
+	data := `{"positions": [
+		{"instrument": "EUR_USD", "long": {"units": "100"}, "short": {"units": "0"}},
+		{"instrument": "USD_JPY", "long": {"units": "50"}, "short": {"units": "-75"}}
+	], "lastTransactionID": "9"}`
+
+	var response PositionsResponse
+	if err := json.Unmarshal([]byte(data), &response); err != nil {
+		t.Fatal(err)
+	}
+	if got := response.Positions[0].NetUnits; got != 100 {
+		t.Errorf("EUR_USD net units = %g, want 100", got)
+	}
+	if got := response.Positions[1].NetUnits; got != -25 {
+		t.Errorf("USD_JPY net units = %g, want -25", got)
+	}
+}
@@ -0,0 +1,116 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+const maxPriceBufferSize = 100000
+
+// PriceBuffer keeps the most recent prices for each instrument in a fixed
+// size ring buffer. It is safe for concurrent use.
+type PriceBuffer struct {
+	mu    sync.RWMutex
+	size  int
+	rings map[string]*priceRing
+}
+
+type priceRing struct {
+	prices []Price
+	next   int
+	full   bool
+}
+
+func NewPriceBuffer(size int) (*PriceBuffer, error) {
+	if size <= 0 || size > maxPriceBufferSize {
+		return nil, fmt.Errorf("price buffer size must be between 1 and %d, got %d", maxPriceBufferSize, size)
+	}
+	return &PriceBuffer{
+		size:  size,
+		rings: make(map[string]*priceRing),
+	}, nil
+}
+
+func (b *PriceBuffer) Add(prices ...Price) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, price := range prices {
+		ring, ok := b.rings[price.Instrument]
+		if !ok {
+			ring = &priceRing{prices: make([]Price, b.size)}
+			b.rings[price.Instrument] = ring
+		}
+		ring.prices[ring.next] = price
+		ring.next = (ring.next + 1) % b.size
+		if ring.next == 0 {
+			ring.full = true
+		}
+	}
+}
+
+// RecentPrices returns up to n of the latest prices for instrument, oldest
+// first. Fewer are returned if the buffer has not filled up yet.
+func (b *PriceBuffer) RecentPrices(instrument string, n int) []Price {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	ring, ok := b.rings[instrument]
+	if !ok || n <= 0 {
+		return nil
+	}
+
+	count := ring.next
+	if ring.full {
+		count = b.size
+	}
+	n = min(n, count)
+
+	recent := make([]Price, n)
+	start := ring.next - n
+	for i := range recent {
+		recent[i] = ring.prices[(start+i+b.size)%b.size]
+	}
+	return recent
+}
+
+// PollPrices fetches prices for instruments every interval and adds them to
+// buf until ctx is cancelled. Failed requests are logged and retried on the
+// next tick.
+func (c *Client) PollPrices(ctx context.Context, instruments []string, interval time.Duration, buf *PriceBuffer) error {
+	for {
+		pricesResponse, err := c.getPrices(instruments, PricingOptions{})
+		if err != nil {
+			log.Printf("Error polling prices: %v", err)
+		} else {
+			buf.Add(pricesResponse.Prices...)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-c.clock.After(interval):
+		}
+	}
+}
+
+// StreamPricesInto adds every price from the pricing stream for instruments
+// to buf until ctx is cancelled or the stream ends. Unlike PollPrices it
+// sees every price, not one per interval, and returns an error when the
+// stream closes so the caller can reconnect.
+func (c *Client) StreamPricesInto(ctx context.Context, instruments []string, buf *PriceBuffer) error {
+	prices, err := c.StreamPrices(ctx, instruments)
+	if err != nil {
+		return err
+	}
+	for price := range prices {
+		buf.Add(price)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return fmt.Errorf("pricing stream for %v closed", instruments)
+}
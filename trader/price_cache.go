@@ -0,0 +1,103 @@
+package trader
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// priceCache keeps the last good price of every instrument the Client has
+// fetched or streamed, along with when it was received.
+type priceCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]cachedPrice
+}
+
+type cachedPrice struct {
+	price     Price
+	fetchedAt time.Time
+}
+
+// WithPriceCache lets getPrices answer from prices fetched less than ttl
+// ago instead of calling OANDA.
+func WithPriceCache(ttl time.Duration) Option {
+	return func(c *Client) {
+		c.priceCache.ttl = ttl
+	}
+}
+
+// WithStalePriceFallback makes getPrices return the last good price of each
+// instrument, with Stale set, when fetching fails with a transient error
+// after any retries. Without a cached price for every requested instrument
+// the error is returned as usual. Callers decide whether stale prices are
+// acceptable.
+func WithStalePriceFallback() Option {
+	return func(c *Client) {
+		c.stalePriceFallback = true
+	}
+}
+
+func (p *priceCache) store(prices []Price, now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.entries == nil {
+		p.entries = make(map[string]cachedPrice)
+	}
+	for _, price := range prices {
+		p.entries[price.Instrument] = cachedPrice{price: price, fetchedAt: now}
+	}
+}
+
+// lookup returns the cached price of every instrument, or false if any is
+// missing or, when maxAge is positive, older than maxAge.
+func (p *priceCache) lookup(instruments []string, now time.Time, maxAge time.Duration) ([]Price, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	prices := make([]Price, 0, len(instruments))
+	for _, instrument := range instruments {
+		entry, ok := p.entries[instrument]
+		if !ok || (maxAge > 0 && now.Sub(entry.fetchedAt) >= maxAge) {
+			return nil, false
+		}
+		prices = append(prices, entry.price)
+	}
+	return prices, true
+}
+
+// maxPrimeBatch is how many instruments PrimeCache prices per request,
+// keeping the query string to a safe length.
+const maxPrimeBatch = 50
+
+// PrimeCache fetches the current price of every instrument and stores it in
+// the price cache, so the first reads after startup don't wait on OANDA.
+// Instruments are priced in batches of up to 50. Batches that fail, or
+// instruments OANDA returns no price for, don't stop the rest; the error
+// lists them all. Prices are cached whether or not WithPriceCache is set,
+// but are only served from the cache with it.
+func (c *Client) PrimeCache(instruments []string) error {
+	var errs []error
+	for batch := range slices.Chunk(instruments, maxPrimeBatch) {
+		pricesResponse, err := c.getPrices(batch, PricingOptions{})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("pricing %s: %w", strings.Join(batch, ","), err))
+			continue
+		}
+
+		received := make(map[string]bool, len(pricesResponse.Prices))
+		for _, price := range pricesResponse.Prices {
+			received[price.Instrument] = true
+		}
+		for _, instrument := range batch {
+			if !received[instrument] {
+				errs = append(errs, fmt.Errorf("no price received for %s", instrument))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,105 @@
+package trader
+
+import (
+	"math"
+	"strconv"
+)
+
+// NoPriceBound passed as a market order's price bound sends the order
+// without one.
+const NoPriceBound float32 = 0
+
+// RoundingMode controls how a market order's price bound is rounded to the
+// instrument's precision.
+type RoundingMode int
+
+const (
+	// RoundAwayFromMarket rounds a buy's bound up and a sell's bound down,
+	// so rounding only ever widens the bound. A buy bound of 1.085054 on
+	// EUR_USD becomes 1.08506 and a sell bound 1.08505. This is the
+	// default.
+	RoundAwayFromMarket RoundingMode = iota
+	// RoundNearest rounds half away from zero whatever the side, which can
+	// leave the bound fractionally tighter than asked.
+	RoundNearest
+)
+
+// WithoutPriceBounds sends every market order in instruments without a
+// price bound, whatever bound the caller gives. See placeMarketOrder for the
+// fill risk.
+func WithoutPriceBounds(instruments ...string) Option {
+	return func(c *Client) {
+		if c.unboundedInstruments == nil {
+			c.unboundedInstruments = make(map[string]bool)
+		}
+		for _, instrument := range instruments {
+			c.unboundedInstruments[instrument] = true
+		}
+	}
+}
+
+// WithPriceBoundRounding sets how price bounds are rounded.
+func WithPriceBoundRounding(mode RoundingMode) Option {
+	return func(c *Client) {
+		c.boundRounding = mode
+	}
+}
+
+// formatPriceBound formats bound for an order of units in instrument at the
+// instrument's display precision, rounded according to the Client's mode.
+// The precision comes from cached instrument metadata when there is some,
+// so no request is made just to format a bound. NoPriceBound, or an
+// instrument set up WithoutPriceBounds, gives an empty string so the field
+// is left out of the order.
+func (c *Client) formatPriceBound(instrument string, units int, bound float32) string {
+	if bound == NoPriceBound || c.unboundedInstruments[instrument] {
+		return ""
+	}
+	precision := c.pricePrecision(instrument)
+
+	// Start from the shortest decimal the float32 stands for, otherwise
+	// 1.08505 would floor to 1.08504 from its binary approximation.
+	value, _ := strconv.ParseFloat(strconv.FormatFloat(float64(bound), 'f', -1, 32), 64)
+	scaled := value * math.Pow10(precision)
+	const epsilon = 1e-6
+	switch {
+	case c.boundRounding == RoundNearest:
+		scaled = math.Round(scaled)
+	case units > 0:
+		scaled = math.Ceil(scaled - epsilon)
+	default:
+		scaled = math.Floor(scaled + epsilon)
+	}
+	return strconv.FormatFloat(scaled/math.Pow10(precision), 'f', precision, 64)
+}
+
+// pricePrecision is the number of decimal places instrument is quoted to.
+// Cached metadata wins, then WithPricePrecision, then the built-in table,
+// and failing all of those it is guessed from the pip location with one
+// fractional pip digit.
+func (c *Client) pricePrecision(instrument string) int {
+	c.mu.Lock()
+	metadata, ok := c.instruments[instrument]
+	c.mu.Unlock()
+	if ok {
+		return metadata.DisplayPrecision
+	}
+	if places, ok := c.pricePrecisions[instrument]; ok {
+		return places
+	}
+	if places, ok := defaultPricePrecision[instrument]; ok {
+		return places
+	}
+	return 1 - defaultPipLocation(instrument)
+}
+
+// PricesEqual reports whether a and b round to the same price at
+// instrument's display precision, so float32 noise from parsing or
+// arithmetic doesn't read as a price change. The precision comes from the
+// same sources as price formatting: cached instrument metadata, then
+// WithPricePrecision and the built-in table, and otherwise one place beyond
+// the pip, i.e. 5 places or 3 for JPY pairs and metals.
+func (c *Client) PricesEqual(a, b float32, instrument string) bool {
+	scale := math.Pow10(c.pricePrecision(instrument))
+	return math.Round(float64(a)*scale) == math.Round(float64(b)*scale)
+}
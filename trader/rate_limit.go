@@ -0,0 +1,48 @@
+package trader
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// rateLimiter spaces requests evenly, at most one every interval, across
+// every goroutine using a Client.
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// WithRateLimit sends at most perSecond REST requests a second from the
+// Client, retries included, delaying requests rather than failing them.
+// OANDA allows around 100 a second on a connection and answers faster
+// clients with 429s. Streams are not limited. There is no limit by default.
+func WithRateLimit(perSecond int) Option {
+	return func(c *Client) {
+		if perSecond > 0 {
+			c.rateLimiter = &rateLimiter{interval: time.Second / time.Duration(perSecond)}
+		}
+	}
+}
+
+// wait blocks until the caller may send a request, or ctx ends.
+func (l *rateLimiter) wait(ctx context.Context, clock Clock) error {
+	l.mu.Lock()
+	now := clock.Now()
+	slot := l.next
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next = slot.Add(l.interval)
+	l.mu.Unlock()
+
+	if delay := slot.Sub(now); delay > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-clock.After(delay):
+		}
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package trader
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterSpacesRequests(t *testing.T) {
+	start := time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	limiter := &rateLimiter{interval: 100 * time.Millisecond}
+
+	if err := limiter.wait(context.Background(), clock); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error)
+	go func() { done <- limiter.wait(context.Background(), clock) }()
+	select {
+	case <-done:
+		t.Fatal("second request was not delayed")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	clock.Advance(100 * time.Millisecond)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
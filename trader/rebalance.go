@@ -0,0 +1,109 @@
+package trader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"time"
+)
+
+// RebalanceStrategy is a portfolio strategy that states the position it
+// wants in each instrument rather than individual orders. Targets returns
+// signed target units per instrument; instruments left out are not
+// managed, and a target of zero closes the position.
+type RebalanceStrategy interface {
+	Targets(ctx context.Context) (map[string]int, error)
+}
+
+// RebalanceOrder is one order needed to move a position to its target.
+type RebalanceOrder struct {
+	Instrument string
+	Current    int
+	Target     int
+	Units      int
+	Response   *OrderResponse
+	Err        error
+}
+
+// planRebalance returns an order for each instrument whose net position
+// differs from its target, in instrument order.
+func planRebalance(targets map[string]int, positions []Position) []RebalanceOrder {
+	current := make(map[string]int, len(positions))
+	for _, position := range positions {
+		current[position.Instrument] = int(position.NetUnits)
+	}
+
+	var orders []RebalanceOrder
+	for instrument, target := range targets {
+		if delta := target - current[instrument]; delta != 0 {
+			orders = append(orders, RebalanceOrder{
+				Instrument: instrument,
+				Current:    current[instrument],
+				Target:     target,
+				Units:      delta,
+			})
+		}
+	}
+	sort.Slice(orders, func(i, j int) bool { return orders[i].Instrument < orders[j].Instrument })
+	return orders
+}
+
+// Rebalance compares targets with the open positions and places one market
+// order per instrument for the difference. Differences smaller than the
+// instrument's minimum trade size are left alone. Orders are sent without a
+// price bound so the portfolio doesn't end up half rebalanced, and net
+// positions are assumed, so use it on netting accounts. Every planned order
+// is returned with its outcome; the error joins any that failed.
+func (c *Client) Rebalance(ctx context.Context, targets map[string]int) ([]RebalanceOrder, error) {
+	positions, err := c.getOpenPositions()
+	if err != nil {
+		return nil, err
+	}
+
+	var placed []RebalanceOrder
+	var errs []error
+	for _, order := range planRebalance(targets, positions) {
+		if err := ctx.Err(); err != nil {
+			return placed, err
+		}
+
+		instrument, err := c.instrument(order.Instrument)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", order.Instrument, err))
+			continue
+		}
+		if math.Abs(float64(order.Units)) < instrument.MinimumTradeSize {
+			continue
+		}
+
+		order.Response, order.Err = c.placeMarketOrder(order.Units, order.Instrument, NoPriceBound)
+		if order.Err != nil {
+			errs = append(errs, fmt.Errorf("%s %+d units: %w", order.Instrument, order.Units, order.Err))
+		}
+		placed = append(placed, order)
+	}
+	return placed, errors.Join(errs...)
+}
+
+// RunRebalanceStrategy asks strategy for its targets and rebalances to them
+// every interval until ctx is cancelled. Errors are logged and the next
+// round goes ahead on schedule.
+func (c *Client) RunRebalanceStrategy(ctx context.Context, strategy RebalanceStrategy, interval time.Duration) error {
+	for {
+		targets, err := strategy.Targets(ctx)
+		if err != nil {
+			log.Printf("Error getting rebalance targets: %v", err)
+		} else if _, err := c.Rebalance(ctx, targets); err != nil {
+			log.Printf("Error rebalancing: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-c.clock.After(interval):
+		}
+	}
+}
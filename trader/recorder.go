@@ -0,0 +1,63 @@
+package trader
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"time"
+)
+
+// RecordStream streams prices for instruments and writes each one to w as a
+// line of JSON until ctx is cancelled or the stream ends. Prices keep OANDA's
+// timestamps so ReplayStream can reproduce the original timing, gaps
+// included.
+func (c *Client) RecordStream(ctx context.Context, instruments []string, w io.Writer) error {
+	prices, err := c.StreamPrices(ctx, instruments)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(w)
+	for price := range prices {
+		if err := encoder.Encode(price); err != nil {
+			return err
+		}
+	}
+	return ctx.Err()
+}
+
+// ReplayStream reads prices written by RecordStream and sends them on the
+// returned channel, waiting between prices for the recorded interval divided
+// by speed on the Client's clock. A speed of 2 replays twice as fast; zero
+// or less replays without waiting. The channel is closed at the end of r.
+func (c *Client) ReplayStream(r io.Reader, speed float64) <-chan Price {
+	prices := make(chan Price)
+	go func() {
+		defer close(prices)
+
+		var last time.Time
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			var price Price
+			if err := json.Unmarshal(scanner.Bytes(), &price); err != nil {
+				log.Printf("Error decoding recorded price: %v", err)
+				continue
+			}
+
+			if speed > 0 && !last.IsZero() && price.Time.After(last) {
+				<-c.clock.After(time.Duration(float64(price.Time.Sub(last)) / speed))
+			}
+			if !price.Time.IsZero() {
+				last = price.Time
+			}
+			prices <- price
+		}
+		if err := scanner.Err(); err != nil {
+			log.Printf("Error reading recorded prices: %v", err)
+		}
+	}()
+
+	return prices
+}
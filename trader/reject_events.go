@@ -0,0 +1,77 @@
+package trader
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// RejectEvent describes an order-related request that OANDA refused with a
+// non-2xx status. ErrorCode and ErrorMessage are parsed from ResponseBody
+// when OANDA included them; for a market order ErrorCode is its reject
+// reason.
+type RejectEvent struct {
+	Time           time.Time
+	Method         string
+	Endpoint       string
+	Instrument     string
+	IdempotencyKey string
+	StatusCode     int
+	RequestBody    []byte
+	ResponseBody   string
+	ErrorCode      string
+	ErrorMessage   string
+	Err            error
+}
+
+// WithOnReject calls fn for every order placement, replacement, close or
+// dependent order update that OANDA rejects, separately from the log, so
+// rejections can be alerted on. fn runs on the calling goroutine before the
+// error is returned, so it should hand off anything slow.
+func WithOnReject(fn func(RejectEvent)) Option {
+	return func(c *Client) {
+		c.onReject = fn
+	}
+}
+
+// reportReject fires the OnReject callback when err is a rejected non-GET
+// request.
+func (c *Client) reportReject(method, endpoint string, payload any, jsonBody []byte, err error) {
+	var apiErr *APIError
+	if c.onReject == nil || method == "GET" || !errors.As(err, &apiErr) {
+		return
+	}
+
+	event := RejectEvent{
+		Time:         c.clock.Now(),
+		Method:       method,
+		Endpoint:     endpoint,
+		StatusCode:   apiErr.StatusCode,
+		RequestBody:  jsonBody,
+		ResponseBody: apiErr.Body,
+		Err:          err,
+	}
+	switch request := payload.(type) {
+	case MarketOrderRequest:
+		event.Instrument = request.Order.Instrument
+		if request.Order.ClientExtensions != nil {
+			event.IdempotencyKey = request.Order.ClientExtensions.ID
+		}
+	case map[string]OrderSpec:
+		spec := request["order"]
+		event.Instrument = spec.Instrument
+		if spec.ClientExtensions != nil {
+			event.IdempotencyKey = spec.ClientExtensions.ID
+		}
+	}
+
+	var body OrderResponse
+	if json.Unmarshal([]byte(apiErr.Body), &body) == nil {
+		event.ErrorCode, event.ErrorMessage = body.ErrorCode, body.ErrorMessage
+		if reject := body.OrderRejectTransaction; reject != nil && reject.RejectReason != "" {
+			event.ErrorCode = reject.RejectReason
+		}
+	}
+
+	c.onReject(event)
+}
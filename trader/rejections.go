@@ -0,0 +1,163 @@
+package trader
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+)
+
+// RejectionAction says how to respond to an order OANDA rejected.
+type RejectionAction int
+
+const (
+	// RejectionSkip drops the order. It is the default for unknown codes.
+	RejectionSkip RejectionAction = iota
+	// RejectionRetry means the order may succeed later unchanged, e.g.
+	// once the market reopens. It is advisory: the Client never resends an
+	// order itself, since only the caller knows whether the signal is
+	// still valid. Check RejectionError.Action to decide.
+	RejectionRetry
+	// RejectionHalt stops the Client placing further orders until
+	// ResumeTrading is called.
+	RejectionHalt
+)
+
+var ErrTradingHalted = errors.New("trading is halted after a rejected order")
+
+func (a RejectionAction) String() string {
+	switch a {
+	case RejectionRetry:
+		return "retry"
+	case RejectionHalt:
+		return "halt"
+	default:
+		return "skip"
+	}
+}
+
+// Account problems halt trading, temporary market conditions are worth
+// retrying, and anything else, including invalid units or prices, is a bug
+// in the order and is skipped.
+var defaultRejectionActions = map[string]RejectionAction{
+	"INSUFFICIENT_MARGIN":           RejectionHalt,
+	"MARGIN_RATE_EXCEEDED":          RejectionHalt,
+	"ACCOUNT_NOT_ACTIVE":            RejectionHalt,
+	"ACCOUNT_LOCKED":                RejectionHalt,
+	"ACCOUNT_ORDER_CREATION_LOCKED": RejectionHalt,
+	"ACCOUNT_CONFIGURATION_LOCKED":  RejectionHalt,
+
+	"MARKET_HALTED":          RejectionRetry,
+	"INSUFFICIENT_LIQUIDITY": RejectionRetry,
+	"PRICE_BOUND_EXCEEDED":   RejectionRetry,
+	"BOUNDS_VIOLATION":       RejectionRetry,
+}
+
+// ClassifyRejection returns the default action for an OANDA reject reason
+// or error code.
+func ClassifyRejection(errorCode string) RejectionAction {
+	return defaultRejectionActions[errorCode]
+}
+
+// RejectionPolicy overrides the default action for individual error codes.
+type RejectionPolicy map[string]RejectionAction
+
+func (p RejectionPolicy) Classify(errorCode string) RejectionAction {
+	if action, ok := p[errorCode]; ok {
+		return action
+	}
+	return ClassifyRejection(errorCode)
+}
+
+// WithRejectionPolicy sets how rejected market orders are classified. The
+// policy is copied.
+func WithRejectionPolicy(policy RejectionPolicy) Option {
+	return func(c *Client) {
+		c.rejectionPolicy = maps.Clone(policy)
+	}
+}
+
+// RejectionError is returned when OANDA rejects a market order. It wraps
+// the *APIError of the response.
+type RejectionError struct {
+	Code    string
+	Message string
+	Action  RejectionAction
+	Err     error
+}
+
+func (e *RejectionError) Error() string {
+	return fmt.Sprintf("order rejected with %s (%s): %s", e.Code, e.Action, e.Message)
+}
+
+func (e *RejectionError) Unwrap() error {
+	return e.Err
+}
+
+// classifyOrderError turns an order submission error carrying an OANDA
+// error code into a *RejectionError and halts trading if the policy says
+// to. Other errors are returned unchanged.
+func (c *Client) classifyOrderError(err error) error {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+
+	var body OrderResponse
+	if json.Unmarshal([]byte(apiErr.Body), &body) != nil {
+		return err
+	}
+	code := body.ErrorCode
+	if body.OrderRejectTransaction != nil && body.OrderRejectTransaction.RejectReason != "" {
+		code = body.OrderRejectTransaction.RejectReason
+	}
+	if code == "" {
+		return err
+	}
+	return c.applyRejection(code, body.ErrorMessage, err)
+}
+
+// classifyOrderCancel applies the policy to a market order OANDA accepted
+// but cancelled instead of filling, which it reports with a 201 and the
+// reason in the cancel transaction. Margin and market halts on FOK orders
+// arrive this way. The response is still returned to the caller without an
+// error, but a reason classified as RejectionHalt halts trading.
+func (c *Client) classifyOrderCancel(response *OrderResponse) {
+	cancel := response.OrderCancelTransaction
+	if cancel == nil || cancel.Reason == "" {
+		return
+	}
+	c.applyRejection(cancel.Reason, "order cancelled", nil)
+}
+
+func (c *Client) applyRejection(code, message string, err error) *RejectionError {
+	rejection := &RejectionError{
+		Code:    code,
+		Message: message,
+		Action:  c.rejectionPolicy.Classify(code),
+		Err:     err,
+	}
+	if rejection.Action == RejectionHalt {
+		c.mu.Lock()
+		c.haltedBy = rejection
+		c.mu.Unlock()
+	}
+	return rejection
+}
+
+// checkHalted refuses orders while trading is halted by a rejection.
+func (c *Client) checkHalted() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.haltedBy != nil {
+		return fmt.Errorf("%w: %s", ErrTradingHalted, c.haltedBy.Code)
+	}
+	return nil
+}
+
+// ResumeTrading lifts a halt caused by a rejected order.
+func (c *Client) ResumeTrading() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.haltedBy = nil
+}
@@ -0,0 +1,85 @@
+package trader
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrRetryBudgetExhausted = errors.New("retry budget exhausted")
+
+// retryBudget is a token bucket of retries shared by every request on a
+// Client, so a broad outage can't turn many concurrent calls into a flood
+// of retries.
+type retryBudget struct {
+	mu        sync.Mutex
+	perMinute float64
+	burst     float64
+	tokens    float64
+	updated   time.Time
+	exhausted int
+}
+
+// RetryBudgetStatus is a snapshot of the retry budget. Exhausted counts the
+// retries refused since the Client was created.
+type RetryBudgetStatus struct {
+	PerMinute float64
+	Burst     int
+	Available float64
+	Exhausted int
+}
+
+// WithRetryBudget caps the retries WithRetry may make across the whole
+// Client at perMinute, allowing bursts of up to burst. A request that would
+// retry while the budget is empty fails straight away with its last error
+// wrapped in ErrRetryBudgetExhausted. There is no budget by default.
+func WithRetryBudget(perMinute float64, burst int) Option {
+	return func(c *Client) {
+		c.retryBudget = &retryBudget{
+			perMinute: perMinute,
+			burst:     float64(burst),
+			tokens:    float64(burst),
+		}
+	}
+}
+
+func (b *retryBudget) refill(now time.Time) {
+	if !b.updated.IsZero() {
+		b.tokens = min(b.burst, b.tokens+now.Sub(b.updated).Minutes()*b.perMinute)
+	}
+	b.updated = now
+}
+
+// take spends one retry, reporting false if none is left.
+func (b *retryBudget) take(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(now)
+	if b.tokens < 1 {
+		b.exhausted++
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// RetryBudget reports the state of the budget set by WithRetryBudget, or
+// nil when there is none.
+func (c *Client) RetryBudget() *RetryBudgetStatus {
+	b := c.retryBudget
+	if b == nil {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(c.clock.Now())
+	return &RetryBudgetStatus{
+		PerMinute: b.perMinute,
+		Burst:     int(b.burst),
+		Available: b.tokens,
+		Exhausted: b.exhausted,
+	}
+}
@@ -0,0 +1,179 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// RiskReport is a snapshot of every open trade and what it stands to lose.
+type RiskReport struct {
+	GeneratedAt time.Time
+	Currency    string
+	NAV         float64
+	Trades      []TradeRisk
+}
+
+// TradeRisk describes one open trade. Current is the price the trade would
+// close at now. StopDistance is how far the price can move against the
+// trade before its stop loss or trailing stop triggers, and RiskPct is the
+// share of NAV lost if it does. Both are zero when HasStop is false, in
+// which case the loss is unbounded.
+type TradeRisk struct {
+	TradeID      string
+	Instrument   string
+	Units        float64
+	Entry        float64
+	Current      float64
+	UnrealizedPL float64
+	HasStop      bool
+	StopPrice    float64
+	StopDistance float64
+	RiskPct      float64
+}
+
+// OpenRiskReport gathers the open trades, their current prices and the
+// account NAV into a RiskReport.
+func (c *Client) OpenRiskReport(ctx context.Context) (*RiskReport, error) {
+	trades, err := c.getOpenTrades()
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	summaryResponse, err := c.getAccountSummary()
+	if err != nil {
+		return nil, err
+	}
+	report := RiskReport{
+		GeneratedAt: c.clock.Now(),
+		Currency:    summaryResponse.Account.Currency,
+		NAV:         summaryResponse.Account.NAV,
+	}
+	if len(trades) == 0 {
+		return &report, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var instruments []string
+	seen := make(map[string]bool)
+	for _, trade := range trades {
+		if !seen[trade.Instrument] {
+			seen[trade.Instrument] = true
+			instruments = append(instruments, trade.Instrument)
+		}
+	}
+	pricesResponse, err := c.getPrices(instruments, PricingOptions{IncludeHomeConversions: true})
+	if err != nil {
+		return nil, err
+	}
+
+	prices := make(map[string]Price, len(pricesResponse.Prices))
+	for _, price := range pricesResponse.Prices {
+		prices[price.Instrument] = price
+	}
+	lossFactors := make(map[string]float64, len(pricesResponse.HomeConversions))
+	for _, conversion := range pricesResponse.HomeConversions {
+		lossFactors[conversion.Currency] = conversion.AccountLoss
+	}
+
+	for _, trade := range trades {
+		price, ok := prices[trade.Instrument]
+		if !ok {
+			return nil, fmt.Errorf("no price received for %s", trade.Instrument)
+		}
+
+		risk := TradeRisk{
+			TradeID:      trade.ID,
+			Instrument:   trade.Instrument,
+			Units:        trade.CurrentUnits,
+			Entry:        trade.Price,
+			Current:      float64(price.Bid),
+			UnrealizedPL: trade.UnrealizedPL,
+		}
+		if trade.CurrentUnits < 0 {
+			risk.Current = float64(price.Ask)
+		}
+
+		risk.StopPrice, risk.HasStop = stopPrice(&trade, risk.Current)
+		if risk.HasStop {
+			risk.StopDistance = risk.Current - risk.StopPrice
+			if trade.CurrentUnits < 0 {
+				risk.StopDistance = -risk.StopDistance
+			}
+
+			_, quote, _ := strings.Cut(trade.Instrument, "_")
+			if factor, ok := lossFactors[quote]; ok && report.NAV > 0 {
+				risk.RiskPct = math.Abs(trade.CurrentUnits) * risk.StopDistance * factor / report.NAV * 100
+			}
+		}
+		report.Trades = append(report.Trades, risk)
+	}
+
+	return &report, nil
+}
+
+// stopPrice returns the tightest of the trade's stop loss, guaranteed stop
+// loss and trailing stop, the trailing stop being placed its distance from
+// current.
+func stopPrice(trade *Trade, current float64) (float64, bool) {
+	long := trade.CurrentUnits > 0
+	var stop float64
+	found := false
+	consider := func(level float64) {
+		if !found || (long && level > stop) || (!long && level < stop) {
+			stop, found = level, true
+		}
+	}
+
+	for _, order := range []*DependentOrder{trade.StopLossOrder, trade.GuaranteedStopLossOrder} {
+		if order == nil {
+			continue
+		}
+		if level, err := strconv.ParseFloat(order.Price, 64); err == nil {
+			consider(level)
+		}
+	}
+	if order := trade.TrailingStopLossOrder; order != nil {
+		if distance, err := strconv.ParseFloat(order.Distance, 64); err == nil {
+			if long {
+				consider(current - distance)
+			} else {
+				consider(current + distance)
+			}
+		}
+	}
+	return stop, found
+}
+
+// WriteText renders the report as an aligned table.
+func (r *RiskReport) WriteText(w io.Writer) error {
+	fmt.Fprintf(w, "Risk report at %s, NAV %.2f %s\n", r.GeneratedAt.Format(time.RFC3339), r.NAV, r.Currency)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "Trade\tInstrument\tUnits\tEntry\tCurrent\tUnrealized P/L\tStop\tDistance\tRisk %\t")
+	var totalPL, totalRisk float64
+	for _, t := range r.Trades {
+		stop, distance, riskPct := "none", "-", "-"
+		if t.HasStop {
+			stop = strconv.FormatFloat(t.StopPrice, 'f', -1, 64)
+			distance = strconv.FormatFloat(t.StopDistance, 'f', 5, 64)
+			riskPct = fmt.Sprintf("%.2f", t.RiskPct)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%g\t%g\t%g\t%.2f\t%s\t%s\t%s\t\n",
+			t.TradeID, t.Instrument, t.Units, t.Entry, t.Current, t.UnrealizedPL, stop, distance, riskPct)
+		totalPL += t.UnrealizedPL
+		totalRisk += t.RiskPct
+	}
+	fmt.Fprintf(tw, "Total\t\t\t\t\t%.2f\t\t\t%.2f\t\n", totalPL, totalRisk)
+	return tw.Flush()
+}
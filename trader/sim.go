@@ -0,0 +1,232 @@
+package trader
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// FillModel decides the price SimClient fills market orders at. Buys fill
+// at the ask and sells at the bid, including sells that close a long and
+// buys that close a short, so the spread is paid on every trade. FillAtMid
+// fills both sides at the mid instead for zero-spread testing.
+// SlippagePips is then added against the order, raising buy fills and
+// lowering sell fills.
+type FillModel struct {
+	SlippagePips float64
+	FillAtMid    bool
+}
+
+// SimClient is an offline stand-in for the Client that fills market orders
+// against quotes fed in with SetPrice. Positions are netted per instrument
+// and P/L is booked in the instrument's quote currency, which is treated
+// as the account currency.
+type SimClient struct {
+	mu        sync.Mutex
+	fillModel FillModel
+	clock     Clock
+	balance   float64
+	prices    map[string]Price
+	positions map[string]*simPosition
+	lastID    int
+
+	faultConfig FaultConfig
+	faults      *faultInjector
+
+	defaultMarginRate float64
+	marginRates       map[string]float64
+}
+
+type simPosition struct {
+	tradeID      string
+	units        float64
+	averagePrice float64
+}
+
+type SimOption func(*SimClient)
+
+func WithFillModel(model FillModel) SimOption {
+	return func(s *SimClient) {
+		s.fillModel = model
+	}
+}
+
+func WithSimClock(clock Clock) SimOption {
+	return func(s *SimClient) {
+		s.clock = clock
+	}
+}
+
+func NewSimClient(balance float64, opts ...SimOption) *SimClient {
+	s := &SimClient{
+		clock:     realClock{},
+		balance:   balance,
+		prices:    make(map[string]Price),
+		positions: make(map[string]*simPosition),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.faults = newFaultInjector(s.faultConfig)
+	return s
+}
+
+// SetPrice updates the quote orders in price.Instrument fill against.
+func (s *SimClient) SetPrice(price Price) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prices[price.Instrument] = price
+}
+
+func (s *SimClient) Balance() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.balance
+}
+
+// Position returns the net signed units and average entry price held in
+// instrument.
+func (s *SimClient) Position(instrument string) (units, averagePrice float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if position, ok := s.positions[instrument]; ok {
+		return position.units, position.averagePrice
+	}
+	return 0, 0
+}
+
+func (s *SimClient) nextID() string {
+	s.lastID++
+	return strconv.Itoa(s.lastID)
+}
+
+func (s *SimClient) fillPrice(quote Price, units int) float64 {
+	slippage := s.fillModel.SlippagePips * math.Pow10(defaultPipLocation(quote.Instrument))
+	switch {
+	case s.fillModel.FillAtMid && units > 0:
+		return float64(quote.Bid+quote.Ask)/2 + slippage
+	case s.fillModel.FillAtMid:
+		return float64(quote.Bid+quote.Ask)/2 - slippage
+	case units > 0:
+		return float64(quote.Ask) + slippage
+	default:
+		return float64(quote.Bid) - slippage
+	}
+}
+
+// PlaceMarketOrder fills units of instrument at the FillModel price, or
+// cancels the order like OANDA's FOK when that price is worse than
+// priceBound. A zero priceBound places no bound.
+func (s *SimClient) PlaceMarketOrder(units int, instrument string, priceBound float32) (*OrderResponse, error) {
+	if err := s.injectFaults(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if units == 0 {
+		return nil, fmt.Errorf("order units must be non-zero")
+	}
+	quote, ok := s.prices[instrument]
+	if !ok {
+		return nil, fmt.Errorf("no simulated price set for %s", instrument)
+	}
+	if !quote.Tradeable {
+		return nil, fmt.Errorf("%s is not tradeable", instrument)
+	}
+	if err := s.checkMargin(instrument, units); err != nil {
+		return nil, err
+	}
+
+	now := s.clock.Now().UTC().Format(time.RFC3339Nano)
+	response := OrderResponse{
+		OrderCreateTransaction: OrderCreateTransaction{
+			ID:           s.nextID(),
+			Instrument:   instrument,
+			Units:        strconv.Itoa(units),
+			Type:         "MARKET_ORDER",
+			TimeInForce:  "FOK",
+			PositionFill: "DEFAULT",
+			Reason:       "CLIENT_ORDER",
+			Time:         now,
+		},
+	}
+
+	price := s.fillPrice(quote, units)
+	if priceBound != 0 && ((units > 0 && price > float64(priceBound)) || (units < 0 && price < float64(priceBound))) {
+		response.OrderCancelTransaction = &OrderCancelTransaction{
+			ID:      s.nextID(),
+			Time:    now,
+			Type:    "ORDER_CANCEL",
+			OrderID: response.OrderCreateTransaction.ID,
+			Reason:  "BOUNDS_VIOLATION",
+		}
+		response.LastTransactionID = response.OrderCancelTransaction.ID
+		response.RelatedTransactionIDs = []string{response.OrderCreateTransaction.ID, response.OrderCancelTransaction.ID}
+		return &response, nil
+	}
+
+	fill := OrderFillTransaction{
+		ID:         s.nextID(),
+		Type:       "ORDER_FILL",
+		Time:       now,
+		OrderID:    response.OrderCreateTransaction.ID,
+		Instrument: instrument,
+		Units:      strconv.Itoa(units),
+		Price:      strconv.FormatFloat(price, 'f', -1, 64),
+		Reason:     "MARKET_ORDER",
+	}
+	pl := s.applyFill(&fill, instrument, float64(units), price)
+	s.balance += pl
+	fill.Pl = strconv.FormatFloat(pl, 'f', 2, 64)
+	fill.AccountBalance = strconv.FormatFloat(s.balance, 'f', 2, 64)
+
+	response.OrderFillTransaction = fill
+	response.LastTransactionID = fill.ID
+	response.RelatedTransactionIDs = []string{response.OrderCreateTransaction.ID, fill.ID}
+	return &response, nil
+}
+
+// applyFill nets units into the instrument's position, recording the trade
+// opened or reduced on fill, and returns the realised P/L.
+func (s *SimClient) applyFill(fill *OrderFillTransaction, instrument string, units, price float64) float64 {
+	position, ok := s.positions[instrument]
+	if !ok || position.units == 0 {
+		position = &simPosition{tradeID: fill.ID}
+		s.positions[instrument] = position
+	}
+
+	if position.units == 0 || (position.units > 0) == (units > 0) {
+		total := position.units + units
+		position.averagePrice = (position.averagePrice*position.units + price*units) / total
+		position.units = total
+		fill.TradeOpened = TradeOpened{TradeID: position.tradeID, Units: fill.Units}
+		return 0
+	}
+
+	closed := math.Copysign(math.Min(math.Abs(units), math.Abs(position.units)), -position.units)
+	pl := -closed * (price - position.averagePrice)
+	reduce := TradeReduce{
+		TradeID:    position.tradeID,
+		Units:      strconv.FormatFloat(closed, 'f', -1, 64),
+		Price:      fill.Price,
+		RealizedPL: strconv.FormatFloat(pl, 'f', 2, 64),
+	}
+	position.units += closed
+	if position.units != 0 {
+		fill.TradeReduced = &reduce
+	} else {
+		fill.TradesClosed = []TradeReduce{reduce}
+	}
+
+	if remaining := units - closed; remaining != 0 {
+		position.tradeID = fill.ID
+		position.units = remaining
+		position.averagePrice = price
+		fill.TradeOpened = TradeOpened{TradeID: fill.ID, Units: strconv.FormatFloat(remaining, 'f', -1, 64)}
+	}
+	return pl
+}
@@ -0,0 +1,128 @@
+package trader
+
+import (
+	"fmt"
+	"math"
+)
+
+// WithSimLeverage turns on margin checks in the SimClient, requiring
+// 1/leverage of each position's value as margin, e.g. 50 for a 2% margin
+// rate. Without it or WithSimMarginRates orders are never refused for
+// margin.
+func WithSimLeverage(leverage float64) SimOption {
+	return func(s *SimClient) {
+		if leverage > 0 {
+			s.defaultMarginRate = 1 / leverage
+		}
+	}
+}
+
+// WithSimMarginRates sets the margin rate of individual instruments, as in
+// Instrument.MarginRate, overriding WithSimLeverage for them and turning on
+// margin checks.
+func WithSimMarginRates(rates map[string]float64) SimOption {
+	return func(s *SimClient) {
+		if s.marginRates == nil {
+			s.marginRates = make(map[string]float64)
+		}
+		for instrument, rate := range rates {
+			s.marginRates[instrument] = rate
+		}
+	}
+}
+
+func (s *SimClient) marginChecked() bool {
+	return s.defaultMarginRate > 0 || len(s.marginRates) > 0
+}
+
+func (s *SimClient) marginRate(instrument string) float64 {
+	if rate, ok := s.marginRates[instrument]; ok {
+		return rate
+	}
+	return s.defaultMarginRate
+}
+
+// marginFor is the margin needed to hold units of instrument at its current
+// mid price.
+func (s *SimClient) marginFor(instrument string, units float64) float64 {
+	quote := s.prices[instrument]
+	mid := float64(quote.Bid+quote.Ask) / 2
+	return math.Abs(units) * mid * s.marginRate(instrument)
+}
+
+func (s *SimClient) marginUsed() float64 {
+	var used float64
+	for instrument, position := range s.positions {
+		used += s.marginFor(instrument, position.units)
+	}
+	return used
+}
+
+// nav is the balance plus the unrealised P/L of every position at the
+// price it would close at.
+func (s *SimClient) nav() float64 {
+	nav := s.balance
+	for instrument, position := range s.positions {
+		if position.units == 0 {
+			continue
+		}
+		quote := s.prices[instrument]
+		closePrice := float64(quote.Bid)
+		if position.units < 0 {
+			closePrice = float64(quote.Ask)
+		}
+		nav += position.units * (closePrice - position.averagePrice)
+	}
+	return nav
+}
+
+// MarginUsed returns the margin held against the open positions at current
+// prices.
+func (s *SimClient) MarginUsed() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.marginUsed()
+}
+
+// MarginAvailable returns the NAV less the margin used.
+func (s *SimClient) MarginAvailable() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.nav() - s.marginUsed()
+}
+
+// checkMargin refuses an order that would leave more margin used than the
+// NAV covers, the way OANDA rejects it with INSUFFICIENT_MARGIN. Orders that
+// reduce the margin needed are always allowed.
+func (s *SimClient) checkMargin(instrument string, units int) error {
+	if !s.marginChecked() {
+		return nil
+	}
+
+	var current float64
+	if position, ok := s.positions[instrument]; ok {
+		current = position.units
+	}
+	before := s.marginFor(instrument, current)
+	after := s.marginFor(instrument, current+float64(units))
+	if after <= before {
+		return nil
+	}
+
+	used := s.marginUsed() - before + after
+	nav := s.nav()
+	if used <= nav {
+		return nil
+	}
+
+	message := fmt.Sprintf("margin required %.2f exceeds NAV %.2f", used, nav)
+	return &RejectionError{
+		Code:    "INSUFFICIENT_MARGIN",
+		Message: message,
+		Action:  ClassifyRejection("INSUFFICIENT_MARGIN"),
+		Err: &APIError{
+			StatusCode: 400,
+			Body:       fmt.Sprintf(`{"errorCode":"INSUFFICIENT_MARGIN","errorMessage":%q}`, message),
+		},
+	}
+}
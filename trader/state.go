@@ -0,0 +1,195 @@
+package trader
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+const stateVersion = 1
+
+// ClientState is the risk-tracking context a long-running Client needs to
+// resume after a restart. Version is bumped whenever the layout changes.
+type ClientState struct {
+	Version           int            `json:"version"`
+	SavedAt           time.Time      `json:"savedAt"`
+	LastTransactionID string         `json:"lastTransactionID"`
+	DailyBaseline     *DailyBaseline `json:"dailyBaseline,omitempty"`
+	DrawdownPeak      float64        `json:"drawdownPeak"`
+}
+
+// DailyBaseline is the NAV at the start of a trading day, which daily P/L
+// is measured from.
+type DailyBaseline struct {
+	Day string  `json:"day"`
+	NAV float64 `json:"nav"`
+}
+
+// StateStore persists ClientState. Load returns nil and no error when
+// nothing has been saved yet.
+type StateStore interface {
+	Load() (*ClientState, error)
+	Save(state *ClientState) error
+}
+
+// FileStateStore keeps the state as JSON in a single file, replacing it
+// atomically on every save.
+type FileStateStore struct {
+	Path string
+}
+
+func (s *FileStateStore) Load() (*ClientState, error) {
+	data, err := os.ReadFile(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var state ClientState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("decoding state file %s: %w", s.Path, err)
+	}
+	if state.Version > stateVersion {
+		return nil, fmt.Errorf("state file %s has version %d, newer than supported version %d", s.Path, state.Version, stateVersion)
+	}
+	return &state, nil
+}
+
+func (s *FileStateStore) Save(state *ClientState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.Path), filepath.Base(s.Path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.Path)
+}
+
+// WithStateStore restores the Client's state from store when it is created
+// and lets Checkpoint save to it.
+func WithStateStore(store StateStore) Option {
+	return func(c *Client) {
+		c.stateStore = store
+	}
+}
+
+// WithDrawdownTracker includes t's peak in the Client's saved state and
+// restores it on start-up.
+func WithDrawdownTracker(t *DrawdownTracker) Option {
+	return func(c *Client) {
+		c.drawdown = t
+	}
+}
+
+func (c *Client) restoreState() {
+	state, err := c.stateStore.Load()
+	if err != nil {
+		log.Printf("Error loading client state: %v", err)
+		return
+	}
+	if state == nil {
+		return
+	}
+
+	c.mu.Lock()
+	c.lastTransactionID = state.LastTransactionID
+	c.dailyBaseline = state.DailyBaseline
+	c.mu.Unlock()
+	if c.drawdown != nil {
+		c.drawdown.restorePeak(state.DrawdownPeak)
+	}
+}
+
+// State returns a snapshot of the Client's resumable state.
+func (c *Client) State() *ClientState {
+	c.mu.Lock()
+	state := ClientState{
+		Version:           stateVersion,
+		SavedAt:           c.clock.Now(),
+		LastTransactionID: c.lastTransactionID,
+		DailyBaseline:     c.dailyBaseline,
+	}
+	c.mu.Unlock()
+	if c.drawdown != nil {
+		state.DrawdownPeak = c.drawdown.Peak()
+	}
+	return &state
+}
+
+// Checkpoint saves the Client's state to its StateStore every interval, and
+// once more when ctx is cancelled.
+func (c *Client) Checkpoint(ctx context.Context, interval time.Duration) error {
+	if c.stateStore == nil {
+		return fmt.Errorf("checkpointing requires a state store, see WithStateStore")
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			if err := c.stateStore.Save(c.State()); err != nil {
+				return err
+			}
+			return ctx.Err()
+		case <-c.clock.After(interval):
+			if err := c.stateStore.Save(c.State()); err != nil {
+				log.Printf("Error saving client state: %v", err)
+			}
+		}
+	}
+}
+
+func (c *Client) LastTransactionID() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lastTransactionID
+}
+
+// observeTransactionID records id as the latest seen transaction if it is
+// newer than the current one.
+func (c *Client) observeTransactionID(id string) {
+	next, err := strconv.Atoi(id)
+	if err != nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if current, err := strconv.Atoi(c.lastTransactionID); err == nil && current >= next {
+		return
+	}
+	c.lastTransactionID = id
+}
+
+// SetDailyBaseline records nav as the start of day's NAV, replacing any
+// earlier baseline.
+func (c *Client) SetDailyBaseline(day time.Time, nav float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.dailyBaseline = &DailyBaseline{Day: day.Format(time.DateOnly), NAV: nav}
+}
+
+func (c *Client) DailyBaseline() *DailyBaseline {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.dailyBaseline
+}
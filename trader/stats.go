@@ -0,0 +1,82 @@
+package trader
+
+import (
+	"fmt"
+	"math"
+)
+
+// SharpeRatio returns the annualised Sharpe ratio of equity. Returns are
+// simple arithmetic returns between consecutive points, (b1-b0)/b0, not log
+// returns, so they compound the same way as the balance. The points should
+// be evenly spaced bars, such as a daily sample of the curve from
+// BuildEquityCurve, with periodsPerYear bars in a year: 252 for daily bars
+// on trading days or 52 for weekly. riskFreeRate is annual and is spread
+// evenly across the periods. The ratio is the mean per-period excess return
+// over its standard deviation, scaled by the square root of periodsPerYear.
+func SharpeRatio(equity []EquityPoint, riskFreeRate float64, periodsPerYear int) (float64, error) {
+	excess, err := excessReturns(equity, riskFreeRate, periodsPerYear)
+	if err != nil {
+		return 0, err
+	}
+
+	mean := meanOf(excess)
+	var variance float64
+	for _, r := range excess {
+		variance += (r - mean) * (r - mean)
+	}
+	stdDev := math.Sqrt(variance / float64(len(excess)-1))
+	if stdDev == 0 {
+		return 0, fmt.Errorf("returns have no variation, the Sharpe ratio is undefined")
+	}
+	return mean / stdDev * math.Sqrt(float64(periodsPerYear)), nil
+}
+
+// SortinoRatio is SharpeRatio with only the downside counted as risk: the
+// deviation is the root mean square of the excess returns below zero, taken
+// over every period.
+func SortinoRatio(equity []EquityPoint, riskFreeRate float64, periodsPerYear int) (float64, error) {
+	excess, err := excessReturns(equity, riskFreeRate, periodsPerYear)
+	if err != nil {
+		return 0, err
+	}
+
+	var downside float64
+	for _, r := range excess {
+		if r < 0 {
+			downside += r * r
+		}
+	}
+	downsideDev := math.Sqrt(downside / float64(len(excess)))
+	if downsideDev == 0 {
+		return 0, fmt.Errorf("returns have no downside, the Sortino ratio is undefined")
+	}
+	return meanOf(excess) / downsideDev * math.Sqrt(float64(periodsPerYear)), nil
+}
+
+func excessReturns(equity []EquityPoint, riskFreeRate float64, periodsPerYear int) ([]float64, error) {
+	if periodsPerYear <= 0 {
+		return nil, fmt.Errorf("periods per year must be positive, got %d", periodsPerYear)
+	}
+	if len(equity) < 3 {
+		return nil, fmt.Errorf("risk-adjusted ratios need at least 3 equity points, got %d", len(equity))
+	}
+
+	perPeriod := riskFreeRate / float64(periodsPerYear)
+	excess := make([]float64, len(equity)-1)
+	for i := 1; i < len(equity); i++ {
+		prev := equity[i-1].Balance
+		if prev <= 0 {
+			return nil, fmt.Errorf("balance of %g at %s leaves returns undefined", prev, equity[i-1].Time)
+		}
+		excess[i-1] = (equity[i].Balance-prev)/prev - perPeriod
+	}
+	return excess, nil
+}
+
+func meanOf(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
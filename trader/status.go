@@ -0,0 +1,118 @@
+package trader
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	statusURL      = "https://status.oanda.com/api/v2/components.json"
+	statusCacheTTL = time.Minute
+)
+
+type RawStatusResponse struct {
+	Components []struct {
+		Name   string `json:"name"`
+		Status string `json:"status"`
+	} `json:"components"`
+}
+
+// ServiceStatus reports OANDA's published service health. Unlike a call
+// against the trading API it says nothing about whether our credentials are
+// valid, only whether OANDA considers its services up.
+type ServiceStatus struct {
+	Operational bool
+	Components  []ComponentStatus
+	CheckedAt   time.Time
+}
+
+type ComponentStatus struct {
+	Name   string
+	Status string
+	// Available is false during partial and major outages and maintenance.
+	Available bool
+}
+
+var statusCache struct {
+	sync.Mutex
+	status *ServiceStatus
+}
+
+// CheckServiceStatus fetches OANDA's status page. Results are cached for a
+// minute so schedulers can call it before every run.
+func CheckServiceStatus(ctx context.Context) (*ServiceStatus, error) {
+	return checkServiceStatus(ctx, &http.Client{}, realClock{})
+}
+
+// CheckServiceStatus is the package-level CheckServiceStatus sent through
+// the Client's Doer and timed by its clock. The cache is shared with it.
+func (c *Client) CheckServiceStatus(ctx context.Context) (*ServiceStatus, error) {
+	return checkServiceStatus(ctx, c.httpClient, c.clock)
+}
+
+func checkServiceStatus(ctx context.Context, doer Doer, clock Clock) (*ServiceStatus, error) {
+	statusCache.Lock()
+	defer statusCache.Unlock()
+
+	if statusCache.status != nil && clock.Now().Sub(statusCache.status.CheckedAt) < statusCacheTTL {
+		return statusCache.status, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", statusURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("User-Agent", defaultUserAgent)
+
+	resp, err := doer.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := readBody(resp.Body, defaultMaxResponseSize)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != 200 {
+		err := fmt.Errorf("%d response code received, Status API request not working as expected", resp.StatusCode)
+		return nil, err
+	}
+
+	var rawResponse RawStatusResponse
+	err = json.Unmarshal(body, &rawResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	status := parseRawStatus(&rawResponse, clock.Now())
+	statusCache.status = status
+	return status, nil
+}
+
+func parseRawStatus(rawResponse *RawStatusResponse, checkedAt time.Time) *ServiceStatus {
+	status := ServiceStatus{
+		Operational: true,
+		Components:  make([]ComponentStatus, len(rawResponse.Components)),
+		CheckedAt:   checkedAt,
+	}
+
+	for i, rawComponent := range rawResponse.Components {
+		available := rawComponent.Status == "operational" || rawComponent.Status == "degraded_performance"
+		status.Components[i] = ComponentStatus{
+			Name:      rawComponent.Name,
+			Status:    rawComponent.Status,
+			Available: available,
+		}
+		if !available {
+			status.Operational = false
+		}
+	}
+
+	return &status
+}
@@ -0,0 +1,189 @@
+package trader
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// maxStreamLineSize bounds one message of a stream. A price with full depth
+// is a few kilobytes.
+const maxStreamLineSize = 1 << 20
+
+const (
+	streamURL             = "https://stream-fxpractice.oanda.com"
+	pricingStreamEndpoint = "/v3/accounts/{accountID}/pricing/stream"
+)
+
+func (c *Client) openStream(ctx context.Context, endpoint string, query url.Values) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", streamURL+c.accountEndpoint(endpoint), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	c.setHeaders(req)
+	req.URL.RawQuery = query.Encode()
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != 200 {
+		defer resp.Body.Close()
+		body, _ := readBody(resp.Body, c.maxResponseSize)
+		return nil, &APIError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+	return resp, nil
+}
+
+// Heartbeat is sent on the pricing stream about every 5 seconds, whether
+// or not prices are moving.
+type Heartbeat struct {
+	Time time.Time
+}
+
+type RawHeartbeat struct {
+	Type string `json:"type"`
+	Time string `json:"time"`
+}
+
+// StreamPrices opens OANDA's pricing stream for instruments and sends each
+// price on the returned channel. The channel is closed when ctx is cancelled
+// or the stream ends; read errors are logged.
+func (c *Client) StreamPrices(ctx context.Context, instruments []string) (<-chan Price, error) {
+	return c.streamPrices(ctx, instruments, nil)
+}
+
+// StreamPricesWithHeartbeats is StreamPrices that also delivers the stream's
+// heartbeats. Quiet instruments can go minutes without a price, so the
+// heartbeats are the way to tell a live connection from a stalled one: if
+// none arrives for 10 seconds or so, cancel ctx and reconnect. The heartbeat
+// channel holds only the latest heartbeat and never blocks prices; both
+// channels are closed together.
+func (c *Client) StreamPricesWithHeartbeats(ctx context.Context, instruments []string) (<-chan Price, <-chan Heartbeat, error) {
+	heartbeats := make(chan Heartbeat, 1)
+	prices, err := c.streamPrices(ctx, instruments, heartbeats)
+	if err != nil {
+		return nil, nil, err
+	}
+	return prices, heartbeats, nil
+}
+
+// StreamPriceChanges is StreamPrices that only sends a price when its bid or
+// ask differs, by PricesEqual, from the last price sent for the instrument.
+// Repeats that only move liquidity or the timestamp are dropped.
+func (c *Client) StreamPriceChanges(ctx context.Context, instruments []string) (<-chan Price, error) {
+	prices, err := c.StreamPrices(ctx, instruments)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(chan Price)
+	go func() {
+		defer close(changes)
+
+		last := make(map[string]Price)
+		for price := range prices {
+			previous, ok := last[price.Instrument]
+			if ok && c.PricesEqual(previous.Bid, price.Bid, price.Instrument) && c.PricesEqual(previous.Ask, price.Ask, price.Instrument) {
+				continue
+			}
+			last[price.Instrument] = price
+
+			select {
+			case changes <- price:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return changes, nil
+}
+
+func (c *Client) streamPrices(ctx context.Context, instruments []string, heartbeats chan Heartbeat) (<-chan Price, error) {
+	query := url.Values{}
+	query.Add("instruments", strings.Join(instruments, ","))
+	resp, err := c.openStream(ctx, pricingStreamEndpoint, query)
+	if err != nil {
+		return nil, err
+	}
+
+	prices := make(chan Price)
+	go func() {
+		defer close(prices)
+		defer resp.Body.Close()
+		if heartbeats != nil {
+			defer close(heartbeats)
+		}
+
+		scanner := bufio.NewScanner(resp.Body)
+		scanner.Buffer(nil, int(min(c.maxResponseSize, maxStreamLineSize)))
+		for scanner.Scan() {
+			var message struct {
+				Type string `json:"type"`
+			}
+			if err := json.Unmarshal(scanner.Bytes(), &message); err != nil {
+				log.Printf("Error decoding pricing stream message: %v", err)
+				continue
+			}
+
+			switch message.Type {
+			case "PRICE":
+				var rawPrice RawPrice
+				if err := json.Unmarshal(scanner.Bytes(), &rawPrice); err != nil {
+					log.Printf("Error decoding streamed price: %v", err)
+					continue
+				}
+				price, err := parseRawPrice(&rawPrice)
+				if err != nil {
+					log.Printf("Error parsing streamed price: %v", err)
+					continue
+				}
+
+				c.priceCache.store([]Price{*price}, c.clock.Now())
+
+				select {
+				case prices <- *price:
+				case <-ctx.Done():
+					return
+				}
+
+			case "HEARTBEAT":
+				if heartbeats == nil {
+					continue
+				}
+				var rawHeartbeat RawHeartbeat
+				if err := json.Unmarshal(scanner.Bytes(), &rawHeartbeat); err != nil {
+					log.Printf("Error decoding stream heartbeat: %v", err)
+					continue
+				}
+				t, err := time.Parse(time.RFC3339Nano, rawHeartbeat.Time)
+				if err != nil {
+					log.Printf("Error parsing stream heartbeat time: %v", err)
+					continue
+				}
+
+				// Replace an unread heartbeat rather than wait for the
+				// consumer.
+				select {
+				case <-heartbeats:
+				default:
+				}
+				heartbeats <- Heartbeat{Time: t}
+
+			default:
+				log.Printf("Ignoring pricing stream message of type %q", message.Type)
+			}
+		}
+		if err := scanner.Err(); err != nil && ctx.Err() == nil {
+			log.Printf("Error reading pricing stream: %v", err)
+		}
+	}()
+
+	return prices, nil
+}
@@ -0,0 +1,141 @@
+package trader
+
+import (
+	"context"
+	"log"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+const subscriptionRetryDelay = 5 * time.Second
+
+// SubscriptionManager keeps a single pricing stream open for a set of
+// instruments that can change while it runs. OANDA can't alter the
+// instruments of an open stream, so each change opens a new stream and only
+// closes the old one once the new one is connected, leaving no gap beyond
+// the overlap. A stream that drops is reopened after a short delay.
+type SubscriptionManager struct {
+	client  *Client
+	prices  chan Price
+	changed chan struct{}
+
+	mu          sync.Mutex
+	instruments map[string]bool
+}
+
+func NewSubscriptionManager(c *Client) *SubscriptionManager {
+	return &SubscriptionManager{
+		client:      c,
+		prices:      make(chan Price),
+		changed:     make(chan struct{}, 1),
+		instruments: map[string]bool{},
+	}
+}
+
+// Prices returns the merged price channel, closed when Run returns.
+func (s *SubscriptionManager) Prices() <-chan Price {
+	return s.prices
+}
+
+// Subscribe adds instruments to the stream.
+func (s *SubscriptionManager) Subscribe(instruments ...string) {
+	s.update(instruments, true)
+}
+
+// Unsubscribe removes instruments from the stream. Their prices are
+// filtered out straight away, before the stream is reopened, but one price
+// Run was already sending when Unsubscribe was called can still arrive
+// after it returns. Consumers that must never see it should check the
+// instrument themselves.
+func (s *SubscriptionManager) Unsubscribe(instruments ...string) {
+	s.update(instruments, false)
+}
+
+func (s *SubscriptionManager) update(instruments []string, subscribed bool) {
+	s.mu.Lock()
+	for _, instrument := range instruments {
+		if subscribed {
+			s.instruments[instrument] = true
+		} else {
+			delete(s.instruments, instrument)
+		}
+	}
+	s.mu.Unlock()
+
+	select {
+	case s.changed <- struct{}{}:
+	default:
+	}
+}
+
+func (s *SubscriptionManager) subscribed(instrument string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.instruments[instrument]
+}
+
+func (s *SubscriptionManager) snapshot() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Sorted(maps.Keys(s.instruments))
+}
+
+// Run streams prices for the subscribed instruments to Prices until ctx is
+// cancelled. Connection errors are logged and retried.
+func (s *SubscriptionManager) Run(ctx context.Context) error {
+	defer close(s.prices)
+
+	cancel := func() {}
+	defer func() { cancel() }()
+
+	var (
+		stream    <-chan Price
+		connected []string
+		retry     <-chan time.Time
+	)
+	for {
+		want := s.snapshot()
+		switch {
+		case len(want) == 0:
+			cancel()
+			stream, connected = nil, nil
+		case retry == nil && (stream == nil || !slices.Equal(want, connected)):
+			streamCtx, streamCancel := context.WithCancel(ctx)
+			next, err := s.client.StreamPrices(streamCtx, want)
+			if err != nil {
+				streamCancel()
+				log.Printf("Error opening pricing stream for %v: %v", want, err)
+				retry = s.client.clock.After(subscriptionRetryDelay)
+				break
+			}
+			cancel()
+			cancel, stream, connected = streamCancel, next, want
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.changed:
+			retry = nil
+		case <-retry:
+			retry = nil
+		case price, ok := <-stream:
+			if !ok {
+				log.Printf("Pricing stream for %v closed, reconnecting", connected)
+				stream, connected = nil, nil
+				retry = s.client.clock.After(subscriptionRetryDelay)
+				continue
+			}
+			if !s.subscribed(price.Instrument) {
+				continue
+			}
+			select {
+			case s.prices <- price:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package trader
+
+import (
+	"context"
+	"errors"
+)
+
+// Tracer starts a span around each REST call the Client makes, so a call
+// can be traced from the caller's decision through to OANDA's response. It
+// mirrors the parts of OpenTelemetry's trace.Tracer the Client needs,
+// keeping the otel dependency out of this package; an adapter is a few
+// lines:
+//
+//	func (t otelTracer) StartSpan(ctx context.Context, name string) (context.Context, trader.Span) {
+//		ctx, span := t.tracer.Start(ctx, name)
+//		return ctx, otelSpan{span}
+//	}
+//
+// The span is started from the context passed to the call, so it becomes a
+// child of any span already in it.
+type Tracer interface {
+	StartSpan(ctx context.Context, name string) (context.Context, Span)
+}
+
+type Span interface {
+	SetAttribute(key string, value any)
+	RecordError(err error)
+	End()
+}
+
+func WithTracer(tracer Tracer) Option {
+	return func(c *Client) {
+		c.tracer = tracer
+	}
+}
+
+// startSpan starts a span for a request when a Tracer is set. The returned
+// function records the outcome and ends the span.
+func (c *Client) startSpan(ctx context.Context, method, endpoint string, payload any) (context.Context, func(status int, err error)) {
+	if c.tracer == nil {
+		return ctx, func(int, error) {}
+	}
+
+	ctx, span := c.tracer.StartSpan(ctx, "oanda "+method+" "+endpoint)
+	span.SetAttribute("http.method", method)
+	span.SetAttribute("oanda.endpoint", endpoint)
+	if request, ok := payload.(MarketOrderRequest); ok {
+		span.SetAttribute("oanda.instrument", request.Order.Instrument)
+		span.SetAttribute("oanda.units", request.Order.Units)
+	}
+
+	return ctx, func(status int, err error) {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) {
+			status = apiErr.StatusCode
+		}
+		if status != 0 {
+			span.SetAttribute("http.status_code", status)
+		}
+		if err != nil {
+			span.RecordError(err)
+		}
+		span.End()
+	}
+}
@@ -0,0 +1,412 @@
+package trader
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const (
+	openTradesEndpoint  = "/v3/accounts/{accountID}/openTrades"
+	tradeEndpoint       = "/v3/accounts/{accountID}/trades/{tradeID}"
+	closeTradeEndpoint  = "/v3/accounts/{accountID}/trades/{tradeID}/close"
+	tradeOrdersEndpoint = "/v3/accounts/{accountID}/trades/{tradeID}/orders"
+)
+
+var ErrTradeNotFound = errors.New("trade not found")
+
+type TradeResponse struct {
+	Trade             Trade  `json:"trade"`
+	LastTransactionID string `json:"lastTransactionID"`
+}
+
+type TradesResponse struct {
+	Trades            []Trade `json:"trades"`
+	LastTransactionID string  `json:"lastTransactionID"`
+}
+
+type Trade struct {
+	ID                    string            `json:"id"`
+	Instrument            string            `json:"instrument"`
+	Price                 float64           `json:"price,string"`
+	OpenTime              string            `json:"openTime"`
+	State                 string            `json:"state"`
+	InitialUnits          float64           `json:"initialUnits,string"`
+	CurrentUnits          float64           `json:"currentUnits,string"`
+	RealizedPL            float64           `json:"realizedPL,string"`
+	UnrealizedPL          float64           `json:"unrealizedPL,string"`
+	Financing             float64           `json:"financing,string"`
+	ClientExtensions      *ClientExtensions `json:"clientExtensions"`
+	TakeProfitOrder       *DependentOrder   `json:"takeProfitOrder"`
+	StopLossOrder         *DependentOrder   `json:"stopLossOrder"`
+	TrailingStopLossOrder *DependentOrder   `json:"trailingStopLossOrder"`
+
+	GuaranteedStopLossOrder *DependentOrder `json:"guaranteedStopLossOrder"`
+	// GuaranteedExecutionFees is the total paid so far for guaranteed
+	// stops on the trade, in the account's home currency.
+	GuaranteedExecutionFees float64 `json:"guaranteedExecutionFees,string"`
+}
+
+// DependentOrder is a take profit, stop loss, guaranteed stop loss or
+// trailing stop loss order attached to a trade. For a guaranteed stop,
+// GuaranteedExecutionPremium is the premium per unit, in the quote
+// currency, charged if it triggers.
+type DependentOrder struct {
+	ID          string `json:"id"`
+	Type        string `json:"type"`
+	State       string `json:"state"`
+	Price       string `json:"price"`
+	Distance    string `json:"distance"`
+	TimeInForce string `json:"timeInForce"`
+	GtdTime     string `json:"gtdTime"`
+
+	GuaranteedExecutionPremium float64 `json:"guaranteedExecutionPremium,string"`
+}
+
+// DependentOrderUpdate changes one of a trade's dependent orders. Set Cancel
+// to remove the order, otherwise Price or Distance replace the current
+// level. A nil *DependentOrderUpdate leaves the order as it is.
+type DependentOrderUpdate struct {
+	Cancel      bool
+	Price       string
+	Distance    string
+	TimeInForce string
+	GtdTime     string
+}
+
+type TradeOrdersUpdate struct {
+	TakeProfit         *DependentOrderUpdate
+	StopLoss           *DependentOrderUpdate
+	TrailingStopLoss   *DependentOrderUpdate
+	GuaranteedStopLoss *DependentOrderUpdate
+}
+
+type TradeOrdersResponse struct {
+	RelatedTransactionIDs []string      `json:"relatedTransactionIDs"`
+	LastTransactionID     string        `json:"lastTransactionID"`
+	Transactions          []Transaction `json:"-"`
+}
+
+type CloseTradeOptions struct {
+	// DependentOrders is applied after a partial close. A full close makes
+	// OANDA cancel the dependent orders itself, so it is ignored then.
+	DependentOrders *TradeOrdersUpdate
+}
+
+// CloseTradeResult holds the close response, the dependent order update
+// response when one was made, and every transaction from both in ID order.
+type CloseTradeResult struct {
+	Close        *OrderResponse
+	Orders       *TradeOrdersResponse
+	Transactions []Transaction
+}
+
+func (r *TradeOrdersResponse) UnmarshalJSON(data []byte) error {
+	type plain TradeOrdersResponse
+	if err := json.Unmarshal(data, (*plain)(r)); err != nil {
+		return err
+	}
+
+	transactions, err := collectTransactions(data)
+	if err != nil {
+		return err
+	}
+	r.Transactions = transactions
+	return nil
+}
+
+// MarshalJSON encodes the update in the shape OANDA expects, where an absent
+// field leaves an order unchanged and null cancels it.
+func (u TradeOrdersUpdate) MarshalJSON() ([]byte, error) {
+	body := map[string]any{}
+	for name, update := range map[string]*DependentOrderUpdate{
+		"takeProfit":         u.TakeProfit,
+		"stopLoss":           u.StopLoss,
+		"trailingStopLoss":   u.TrailingStopLoss,
+		"guaranteedStopLoss": u.GuaranteedStopLoss,
+	} {
+		if update == nil {
+			continue
+		}
+		if update.Cancel {
+			body[name] = nil
+			continue
+		}
+
+		details := map[string]string{}
+		if update.Price != "" {
+			details["price"] = update.Price
+		}
+		if update.Distance != "" {
+			details["distance"] = update.Distance
+		}
+		if update.TimeInForce != "" {
+			details["timeInForce"] = update.TimeInForce
+		}
+		if update.GtdTime != "" {
+			details["gtdTime"] = update.GtdTime
+		}
+		body[name] = details
+	}
+	return json.Marshal(body)
+}
+
+func tradePath(endpoint, tradeID string) string {
+	return strings.Replace(endpoint, "{tradeID}", tradeID, 1)
+}
+
+func (c *Client) getOpenTrades() ([]Trade, error) {
+	var tradesResponse TradesResponse
+	err := c.do(context.Background(), "GET", openTradesEndpoint, nil, nil, 200, &tradesResponse)
+	if err != nil {
+		return nil, err
+	}
+	return tradesResponse.Trades, nil
+}
+
+func (c *Client) getTrade(tradeID string) (*Trade, error) {
+	var tradeResponse TradeResponse
+	err := c.do(context.Background(), "GET", tradePath(tradeEndpoint, tradeID), nil, nil, 200, &tradeResponse)
+	if err != nil {
+		return nil, err
+	}
+	return &tradeResponse.Trade, nil
+}
+
+// getTradeByClientID looks up a trade by the client extension ID it was
+// opened with rather than OANDA's trade ID. It returns ErrTradeNotFound
+// when no trade carries clientID.
+func (c *Client) getTradeByClientID(clientID string) (*Trade, error) {
+	if clientID == "" {
+		return nil, fmt.Errorf("client ID must not be empty")
+	}
+
+	trade, err := c.getTrade("@" + url.PathEscape(clientID))
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == 404 {
+		return nil, fmt.Errorf("%w: client ID %s", ErrTradeNotFound, clientID)
+	}
+	return trade, err
+}
+
+func (c *Client) setTradeOrders(tradeID string, update TradeOrdersUpdate) (*TradeOrdersResponse, error) {
+	var ordersResponse TradeOrdersResponse
+	err := c.do(context.Background(), "PUT", tradePath(tradeOrdersEndpoint, tradeID), nil, update, 200, &ordersResponse)
+	if err != nil {
+		return nil, err
+	}
+	return &ordersResponse, nil
+}
+
+// closeTrade closes units of a trade, or all of it when units is "ALL". When
+// the close is partial and opts.DependentOrders is set, the trade's stop loss,
+// take profit and trailing stop are updated straight afterwards so they
+// match the smaller position.
+func (c *Client) closeTrade(tradeID string, units string, opts CloseTradeOptions) (*CloseTradeResult, error) {
+	if units == "" {
+		units = "ALL"
+	}
+
+	var closeResponse OrderResponse
+	closeRequest := map[string]string{"units": units}
+	err := c.do(context.Background(), "PUT", tradePath(closeTradeEndpoint, tradeID), nil, closeRequest, 200, &closeResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	result := CloseTradeResult{
+		Close:        &closeResponse,
+		Transactions: closeResponse.Transactions,
+	}
+	if opts.DependentOrders == nil || closeResponse.OrderFillTransaction.TradeReduced == nil {
+		return &result, nil
+	}
+
+	ordersResponse, err := c.setTradeOrders(tradeID, *opts.DependentOrders)
+	if err != nil {
+		return &result, fmt.Errorf("trade %s was reduced but updating its dependent orders failed: %w", tradeID, err)
+	}
+	result.Orders = ordersResponse
+	result.Transactions = append(result.Transactions, ordersResponse.Transactions...)
+	sortTransactions(result.Transactions)
+	return &result, nil
+}
+
+// modifyTradeOrders moves the stop loss and take profit of an open trade. A
+// nil level is left unchanged and a level of zero cancels that order. Levels
+// are checked against the price the trade would close at now, the bid for a
+// long and the ask for a short: the stop loss must be on the losing side of
+// it and the take profit on the winning side. A stop can therefore be moved
+// to breakeven or into profit once the market has moved far enough. The
+// transactions OANDA created are returned.
+func (c *Client) modifyTradeOrders(tradeID string, sl, tp *float32) ([]Transaction, error) {
+	if sl == nil && tp == nil {
+		return nil, fmt.Errorf("no stop loss or take profit change given for trade %s", tradeID)
+	}
+
+	trade, err := c.getTrade(tradeID)
+	if err != nil {
+		return nil, err
+	}
+	instrument, err := c.instrument(trade.Instrument)
+	if err != nil {
+		return nil, err
+	}
+
+	quote, err := c.quote(trade.Instrument)
+	if err != nil {
+		return nil, err
+	}
+	long := trade.CurrentUnits > 0
+	closing := quote.Ask
+	if long {
+		closing = quote.Bid
+	}
+
+	var update TradeOrdersUpdate
+	if sl != nil {
+		if update.StopLoss, err = dependentLevel(*sl, closing, long, instrument); err != nil {
+			return nil, fmt.Errorf("stop loss for trade %s: %w", tradeID, err)
+		}
+	}
+	if tp != nil {
+		if update.TakeProfit, err = dependentLevel(*tp, closing, !long, instrument); err != nil {
+			return nil, fmt.Errorf("take profit for trade %s: %w", tradeID, err)
+		}
+	}
+
+	ordersResponse, err := c.setTradeOrders(tradeID, update)
+	if err != nil {
+		return nil, err
+	}
+	return ordersResponse.Transactions, nil
+}
+
+// dependentLevel builds the update for one dependent order, checking it is
+// below the closing price when below is set and above it otherwise.
+func dependentLevel(level, closing float32, below bool, instrument *Instrument) (*DependentOrderUpdate, error) {
+	if level == 0 {
+		return &DependentOrderUpdate{Cancel: true}, nil
+	}
+	if below && level >= closing {
+		return nil, fmt.Errorf("%g must be below the current closing price of %g", level, closing)
+	}
+	if !below && level <= closing {
+		return nil, fmt.Errorf("%g must be above the current closing price of %g", level, closing)
+	}
+	return &DependentOrderUpdate{Price: instrument.formatPrice(float64(level)), TimeInForce: "GTC"}, nil
+}
+
+// BreakEvenPrice returns the mid price at which closing the trade would
+// leave it flat after financing and the spread. The trade's average fill
+// price is moved by the financing accrued so far, using the financing OANDA
+// reports on the trade, which covers every daily financing charge or credit
+// since it opened. The financing is in the account's home currency, so it
+// is converted back to the quote currency with the current home conversion
+// factor: the gain factor when financing is a cost to be earned back and
+// the loss factor when it is a credit that can be given up. The trade
+// closes on the bid if long and the ask if short, so half the current
+// spread is then added for a long trade and taken off for a short one.
+// Commission is not included.
+func (c *Client) BreakEvenPrice(tradeID string) (float64, error) {
+	trade, err := c.getTrade(tradeID)
+	if err != nil {
+		return 0, err
+	}
+	if trade.CurrentUnits == 0 {
+		return 0, fmt.Errorf("trade %s has no open units", tradeID)
+	}
+
+	_, quote, ok := strings.Cut(trade.Instrument, "_")
+	if !ok {
+		return 0, fmt.Errorf("cannot find the quote currency of %s", trade.Instrument)
+	}
+	isHome := c.quoteIsHome(quote)
+	pricesResponse, err := c.getPrices([]string{trade.Instrument}, PricingOptions{IncludeHomeConversions: !isHome && trade.Financing != 0})
+	if err != nil {
+		return 0, err
+	}
+	if len(pricesResponse.Prices) == 0 {
+		return 0, fmt.Errorf("no price received for %s", trade.Instrument)
+	}
+
+	closing := trade.Price
+	if trade.Financing != 0 {
+		conversion, err := homeConversion(quote, isHome, pricesResponse.HomeConversions)
+		if err != nil {
+			return 0, err
+		}
+		factor := conversion.AccountGain
+		if trade.Financing > 0 {
+			factor = conversion.AccountLoss
+		}
+		if factor == 0 {
+			return 0, fmt.Errorf("zero home conversion received for %s", quote)
+		}
+
+		// Solve units*(price-entry)*factor + financing = 0 for price. The
+		// sign of units handles short trades.
+		closing -= trade.Financing / (trade.CurrentUnits * factor)
+	}
+
+	price := pricesResponse.Prices[0]
+	halfSpread := float64(price.Ask-price.Bid) / 2
+	if trade.CurrentUnits < 0 {
+		return closing - halfSpread, nil
+	}
+	return closing + halfSpread, nil
+}
+
+// LiveUnrealizedPL recomputes the trade's unrealized profit or loss in the
+// home currency from a fresh quote, rather than the figure OANDA last
+// reported:
+//
+//	units * (exit - entry) * factor
+//
+// exit is the bid for a long trade and the ask for a short one, the side it
+// would close at, and units is negative for a short. factor is the quote
+// currency's gain conversion for a profit and its loss conversion for a
+// loss. It can differ slightly from OANDA's own UnrealizedPL, which is
+// valued at OANDA's latest price rather than this quote and computed from
+// unrounded figures; neither includes financing.
+func (c *Client) LiveUnrealizedPL(tradeID string) (float64, error) {
+	trade, err := c.getTrade(tradeID)
+	if err != nil {
+		return 0, err
+	}
+	if trade.CurrentUnits == 0 {
+		return 0, fmt.Errorf("trade %s has no open units", tradeID)
+	}
+
+	_, quote, ok := strings.Cut(trade.Instrument, "_")
+	if !ok {
+		return 0, fmt.Errorf("cannot find the quote currency of %s", trade.Instrument)
+	}
+	isHome := c.quoteIsHome(quote)
+	pricesResponse, err := c.getPrices([]string{trade.Instrument}, PricingOptions{IncludeHomeConversions: !isHome})
+	if err != nil {
+		return 0, err
+	}
+	if len(pricesResponse.Prices) == 0 {
+		return 0, fmt.Errorf("no price received for %s", trade.Instrument)
+	}
+
+	price := pricesResponse.Prices[0]
+	exit := float64(price.Bid)
+	if trade.CurrentUnits < 0 {
+		exit = float64(price.Ask)
+	}
+	pl := trade.CurrentUnits * (exit - trade.Price)
+
+	conversion, err := homeConversion(quote, isHome, pricesResponse.HomeConversions)
+	if err != nil {
+		return 0, err
+	}
+	if pl >= 0 {
+		return pl * conversion.AccountGain, nil
+	}
+	return pl * conversion.AccountLoss, nil
+}
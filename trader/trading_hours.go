@@ -0,0 +1,121 @@
+package trader
+
+import (
+	"strings"
+	"time"
+	_ "time/tzdata"
+)
+
+const minutesPerWeek = 7 * 24 * 60
+
+var newYork = mustLoadLocation("America/New_York")
+
+// weekSpan is a half-open range of minutes since Sunday 00:00 New York time.
+type weekSpan struct {
+	start, end int
+}
+
+func weekMinute(day time.Weekday, hour, minute int) int {
+	return int(day)*24*60 + hour*60 + minute
+}
+
+// OANDA doesn't publish session times through the API, so these follow its
+// published schedule in New York time. FX trades continuously from Sunday
+// 17:00 to Friday 17:00. Metals, indices and other CFDs open an hour later
+// on Sunday and stop for an hour from 17:00 every weekday. Holidays are not
+// covered.
+var (
+	fxSessions = []weekSpan{
+		{weekMinute(time.Sunday, 17, 0), weekMinute(time.Friday, 17, 0)},
+	}
+	cfdSessions = []weekSpan{
+		{weekMinute(time.Sunday, 18, 0), weekMinute(time.Monday, 17, 0)},
+		{weekMinute(time.Monday, 18, 0), weekMinute(time.Tuesday, 17, 0)},
+		{weekMinute(time.Tuesday, 18, 0), weekMinute(time.Wednesday, 17, 0)},
+		{weekMinute(time.Wednesday, 18, 0), weekMinute(time.Thursday, 17, 0)},
+		{weekMinute(time.Thursday, 18, 0), weekMinute(time.Friday, 17, 0)},
+	}
+)
+
+var metals = map[string]bool{"XAU": true, "XAG": true, "XPT": true, "XPD": true}
+
+func mustLoadLocation(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		panic(err)
+	}
+	return loc
+}
+
+func sessionsFor(instrument string) []weekSpan {
+	base, quote, ok := strings.Cut(instrument, "_")
+	if ok && isCurrencyCode(base) && isCurrencyCode(quote) && !metals[base] {
+		return fxSessions
+	}
+	return cfdSessions
+}
+
+func isCurrencyCode(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// IsOpen reports whether instrument is inside a trading session at t.
+func IsOpen(instrument string, t time.Time) bool {
+	minute := minuteOfWeek(t)
+	for _, session := range sessionsFor(instrument) {
+		if minute >= session.start && minute < session.end {
+			return true
+		}
+	}
+	return false
+}
+
+// NextOpen returns t if instrument is open at t, otherwise the start of its
+// next trading session.
+func NextOpen(instrument string, t time.Time) time.Time {
+	if IsOpen(instrument, t) {
+		return t
+	}
+
+	local := t.In(newYork)
+	sunday := time.Date(local.Year(), local.Month(), local.Day()-int(local.Weekday()), 0, 0, 0, 0, newYork)
+	minute := minuteOfWeek(t)
+	for week := 0; week < 2; week++ {
+		for _, session := range sessionsFor(instrument) {
+			if week == 0 && session.start <= minute {
+				continue
+			}
+			days, rest := session.start/(24*60), session.start%(24*60)
+			return time.Date(sunday.Year(), sunday.Month(), sunday.Day()+days+7*week, rest/60, rest%60, 0, 0, newYork)
+		}
+	}
+	return t
+}
+
+// SessionEnd returns when the trading session instrument is in at t
+// closes, or t itself if it is closed at t.
+func SessionEnd(instrument string, t time.Time) time.Time {
+	minute := minuteOfWeek(t)
+	local := t.In(newYork)
+	sunday := time.Date(local.Year(), local.Month(), local.Day()-int(local.Weekday()), 0, 0, 0, 0, newYork)
+	for _, session := range sessionsFor(instrument) {
+		if minute >= session.start && minute < session.end {
+			days, rest := session.end/(24*60), session.end%(24*60)
+			return time.Date(sunday.Year(), sunday.Month(), sunday.Day()+days, rest/60, rest%60, 0, 0, newYork)
+		}
+	}
+	return t
+}
+
+func minuteOfWeek(t time.Time) int {
+	local := t.In(newYork)
+	return weekMinute(local.Weekday(), local.Hour(), local.Minute()) % minutesPerWeek
+}
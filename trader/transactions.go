@@ -0,0 +1,305 @@
+package trader
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	transactionsEndpoint        = "/v3/accounts/{accountID}/transactions"
+	transactionsIDRangeEndpoint = "/v3/accounts/{accountID}/transactions/idrange"
+)
+
+type TransactionPagesResponse struct {
+	From              string   `json:"from"`
+	To                string   `json:"to"`
+	PageSize          int      `json:"pageSize"`
+	Count             int      `json:"count"`
+	Pages             []string `json:"pages"`
+	LastTransactionID string   `json:"lastTransactionID"`
+}
+
+type TransactionsResponse struct {
+	Transactions      []Transaction `json:"transactions"`
+	LastTransactionID string        `json:"lastTransactionID"`
+}
+
+// Transaction holds the fields shared by every OANDA transaction type. Raw
+// keeps the complete JSON so types that aren't modelled below are never
+// lost; use Decode to read it into a typed struct.
+type Transaction struct {
+	ID        string `json:"id"`
+	Time      string `json:"time"`
+	Type      string `json:"type"`
+	AccountID string `json:"accountID"`
+	BatchID   string `json:"batchID"`
+	RequestID string `json:"requestID"`
+	UserID    int    `json:"userID"`
+
+	Raw json.RawMessage `json:"-"`
+}
+
+func (t *Transaction) UnmarshalJSON(data []byte) error {
+	type plain Transaction
+	if err := json.Unmarshal(data, (*plain)(t)); err != nil {
+		return err
+	}
+	t.Raw = append(json.RawMessage(nil), data...)
+	return nil
+}
+
+func (t Transaction) MarshalJSON() ([]byte, error) {
+	if t.Raw != nil {
+		return t.Raw, nil
+	}
+	type plain Transaction
+	return json.Marshal(plain(t))
+}
+
+func (t *Transaction) Decode(v any) error {
+	if t.Raw == nil {
+		return fmt.Errorf("transaction %s has no raw data", t.ID)
+	}
+	return json.Unmarshal(t.Raw, v)
+}
+
+type OrderCancelTransaction struct {
+	ID                string `json:"id"`
+	Time              string `json:"time"`
+	Type              string `json:"type"`
+	OrderID           string `json:"orderID"`
+	ClientOrderID     string `json:"clientOrderID"`
+	Reason            string `json:"reason"`
+	ReplacedByOrderID string `json:"replacedByOrderID"`
+}
+
+type MarketOrderRejectTransaction struct {
+	ID           string `json:"id"`
+	Time         string `json:"time"`
+	Type         string `json:"type"`
+	Instrument   string `json:"instrument"`
+	Units        string `json:"units"`
+	TimeInForce  string `json:"timeInForce"`
+	PositionFill string `json:"positionFill"`
+	Reason       string `json:"reason"`
+	RejectReason string `json:"rejectReason"`
+}
+
+// ProtectiveOrderTransaction covers the creation of STOP_LOSS_ORDER,
+// GUARANTEED_STOP_LOSS_ORDER, TAKE_PROFIT_ORDER and TRAILING_STOP_LOSS_ORDER
+// orders on a trade. GuaranteedExecutionPremium is only set on guaranteed
+// stops and is the premium per unit charged if the stop is triggered.
+type ProtectiveOrderTransaction struct {
+	ID          string `json:"id"`
+	Time        string `json:"time"`
+	Type        string `json:"type"`
+	TradeID     string `json:"tradeID"`
+	Price       string `json:"price"`
+	Distance    string `json:"distance"`
+	TimeInForce string `json:"timeInForce"`
+	GtdTime     string `json:"gtdTime"`
+	Reason      string `json:"reason"`
+
+	GuaranteedExecutionPremium string `json:"guaranteedExecutionPremium"`
+}
+
+// UnmarshalJSON decodes the fixed fields and also gathers every
+// "...Transaction" object in the response, known or not, into Transactions
+// ordered by ID.
+func (r *OrderResponse) UnmarshalJSON(data []byte) error {
+	type plain OrderResponse
+	if err := json.Unmarshal(data, (*plain)(r)); err != nil {
+		return err
+	}
+
+	transactions, err := collectTransactions(data)
+	if err != nil {
+		return err
+	}
+	r.Transactions = transactions
+	return nil
+}
+
+// collectTransactions returns every "...Transaction" object at the top level
+// of a response body, ordered by ID.
+func collectTransactions(data []byte) ([]Transaction, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	var transactions []Transaction
+	for key, raw := range fields {
+		if !strings.HasSuffix(key, "Transaction") || string(raw) == "null" {
+			continue
+		}
+		var txn Transaction
+		if err := json.Unmarshal(raw, &txn); err != nil {
+			return nil, fmt.Errorf("decoding %s: %w", key, err)
+		}
+		transactions = append(transactions, txn)
+	}
+	sortTransactions(transactions)
+	return transactions, nil
+}
+
+func (r *OrderResponse) TransactionsOfType(types ...string) []Transaction {
+	var matched []Transaction
+	for _, txn := range r.Transactions {
+		for _, t := range types {
+			if txn.Type == t {
+				matched = append(matched, txn)
+				break
+			}
+		}
+	}
+	return matched
+}
+
+// ProtectiveOrders returns the stop loss, take profit and trailing stop loss
+// orders created by this request.
+func (r *OrderResponse) ProtectiveOrders() ([]ProtectiveOrderTransaction, error) {
+	var orders []ProtectiveOrderTransaction
+	for _, txn := range r.TransactionsOfType("STOP_LOSS_ORDER", "GUARANTEED_STOP_LOSS_ORDER", "TAKE_PROFIT_ORDER", "TRAILING_STOP_LOSS_ORDER") {
+		var order ProtectiveOrderTransaction
+		if err := txn.Decode(&order); err != nil {
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+	return orders, nil
+}
+
+func sortTransactions(txns []Transaction) {
+	sort.SliceStable(txns, func(i, j int) bool {
+		a, errA := strconv.Atoi(txns[i].ID)
+		b, errB := strconv.Atoi(txns[j].ID)
+		if errA != nil || errB != nil {
+			return txns[i].ID < txns[j].ID
+		}
+		return a < b
+	})
+}
+
+// RequestedUnits returns the signed units the order was created with.
+func (r *OrderResponse) RequestedUnits() (float64, error) {
+	if r.OrderCreateTransaction.Units == "" {
+		return 0, fmt.Errorf("order response has no order create transaction")
+	}
+	return strconv.ParseFloat(r.OrderCreateTransaction.Units, 64)
+}
+
+// FilledUnits returns the signed units that were filled, or zero when the
+// order was not filled at all.
+func (r *OrderResponse) FilledUnits() (float64, error) {
+	if r.OrderFillTransaction.Units == "" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(r.OrderFillTransaction.Units, 64)
+}
+
+// WasPartial reports whether some but not all of the requested units were
+// filled. FOK orders are never partial; IOC orders may be.
+func (r *OrderResponse) WasPartial() bool {
+	requested, err := r.RequestedUnits()
+	if err != nil {
+		return false
+	}
+	filled, err := r.FilledUnits()
+	if err != nil {
+		return false
+	}
+	return filled != 0 && math.Abs(filled) < math.Abs(requested)
+}
+
+// getTransactions returns the transactions between from and to in ID order,
+// limited to types when any are given. OANDA answers a date range with a
+// list of page URLs, each of which is fetched in turn.
+func (c *Client) getTransactions(from, to time.Time, types ...string) ([]Transaction, error) {
+	q := url.Values{}
+	q.Add("from", from.UTC().Format(time.RFC3339))
+	q.Add("to", to.UTC().Format(time.RFC3339))
+	if len(types) > 0 {
+		q.Add("type", strings.Join(types, ","))
+	}
+
+	var pagesResponse TransactionPagesResponse
+	err := c.do(context.Background(), "GET", transactionsEndpoint, q, nil, 200, &pagesResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	var transactions []Transaction
+	for _, page := range pagesResponse.Pages {
+		pageURL, err := url.Parse(page)
+		if err != nil {
+			return nil, fmt.Errorf("invalid transactions page URL %q: %w", page, err)
+		}
+
+		var pageResponse TransactionsResponse
+		err = c.do(context.Background(), "GET", pageURL.Path, pageURL.Query(), nil, 200, &pageResponse)
+		if err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, pageResponse.Transactions...)
+	}
+
+	sortTransactions(transactions)
+	return transactions, nil
+}
+
+// getTransactionRange returns the transactions with IDs from to to
+// inclusive.
+func (c *Client) getTransactionRange(from, to string) ([]Transaction, error) {
+	q := url.Values{}
+	q.Add("from", from)
+	q.Add("to", to)
+
+	var rangeResponse TransactionsResponse
+	err := c.do(context.Background(), "GET", transactionsIDRangeEndpoint, q, nil, 200, &rangeResponse)
+	if err != nil {
+		return nil, err
+	}
+	sortTransactions(rangeResponse.Transactions)
+	return rangeResponse.Transactions, nil
+}
+
+// maxTransactionRange is the most transactions OANDA returns from one
+// idrange request.
+const maxTransactionRange = 1000
+
+// getLastTransactions returns the latest n transactions in ID order, or all
+// of them if the account has fewer. The range is worked out from the
+// account's last transaction ID, so no date paging is needed.
+func (c *Client) getLastTransactions(n int) ([]Transaction, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("transaction count must be positive, got %d", n)
+	}
+
+	summaryResponse, err := c.getAccountSummary()
+	if err != nil {
+		return nil, err
+	}
+	last, err := strconv.Atoi(summaryResponse.LastTransactionID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid last transaction ID %q: %w", summaryResponse.LastTransactionID, err)
+	}
+
+	var transactions []Transaction
+	for from := max(1, last-n+1); from <= last; from += maxTransactionRange {
+		to := min(last, from+maxTransactionRange-1)
+		page, err := c.getTransactionRange(strconv.Itoa(from), strconv.Itoa(to))
+		if err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, page...)
+	}
+	return transactions, nil
+}
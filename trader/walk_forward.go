@@ -0,0 +1,153 @@
+package trader
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// WalkForwardConfig sets the windows of a walk-forward test, in bars. Each
+// window optimises on InSample bars and evaluates on the OutOfSample bars
+// straight after them, then the whole window moves forward Step bars. Step
+// defaults to OutOfSample, so the out-of-sample periods tile the history
+// without overlapping. With Anchored set every in-sample period starts at
+// the first candle and grows by Step each window instead of sliding.
+//
+// PeriodsPerYear, if set, is the number of equity points evaluate returns
+// per year, used for the aggregate Sharpe ratio.
+type WalkForwardConfig struct {
+	InSample       int
+	OutOfSample    int
+	Step           int
+	Anchored       bool
+	PeriodsPerYear int
+}
+
+// WalkForwardWindow is the result of one window. Return is the fractional
+// change in balance over the out-of-sample equity and MaxDrawdown its
+// largest fall from a peak, as a fraction of the peak.
+type WalkForwardWindow struct {
+	InSampleStart    time.Time
+	OutOfSampleStart time.Time
+	OutOfSampleEnd   time.Time
+	Params           any
+	Equity           []EquityPoint
+	Return           float64
+	MaxDrawdown      float64
+}
+
+// WalkForwardResult aggregates the out-of-sample windows. Equity chains
+// the windows' curves, each rescaled to start where the previous one ended,
+// and TotalReturn and MaxDrawdown are measured on it. Sharpe is zero unless
+// PeriodsPerYear was set.
+type WalkForwardResult struct {
+	Windows         []WalkForwardWindow
+	Equity          []EquityPoint
+	TotalReturn     float64
+	MaxDrawdown     float64
+	Sharpe          float64
+	PositiveWindows int
+}
+
+// WalkForward runs a walk-forward test over candles. optimize picks
+// parameters from each in-sample period, and evaluate trades those
+// parameters over the out-of-sample period that follows, returning the
+// resulting equity curve. Only out-of-sample results are reported, so they
+// show how the optimisation holds up on data it hasn't seen. Candles left
+// over after the last full window are not used.
+func WalkForward(candles []Candle, cfg WalkForwardConfig,
+	optimize func(inSample []Candle) (any, error),
+	evaluate func(outOfSample []Candle, params any) ([]EquityPoint, error)) (*WalkForwardResult, error) {
+	if cfg.InSample <= 0 || cfg.OutOfSample <= 0 {
+		return nil, fmt.Errorf("in-sample and out-of-sample windows must be positive, got %d and %d", cfg.InSample, cfg.OutOfSample)
+	}
+	step := cfg.Step
+	if step == 0 {
+		step = cfg.OutOfSample
+	}
+	if step < 0 {
+		return nil, fmt.Errorf("walk-forward step must be positive, got %d", step)
+	}
+	if len(candles) < cfg.InSample+cfg.OutOfSample {
+		return nil, fmt.Errorf("walk-forward needs at least %d candles, got %d", cfg.InSample+cfg.OutOfSample, len(candles))
+	}
+
+	var result WalkForwardResult
+	for start := 0; start+cfg.InSample+cfg.OutOfSample <= len(candles); start += step {
+		inStart := start
+		if cfg.Anchored {
+			inStart = 0
+		}
+		split := start + cfg.InSample
+		end := split + cfg.OutOfSample
+
+		params, err := optimize(candles[inStart:split])
+		if err != nil {
+			return nil, fmt.Errorf("optimising window starting %s: %w", candles[inStart].Time, err)
+		}
+		equity, err := evaluate(candles[split:end], params)
+		if err != nil {
+			return nil, fmt.Errorf("evaluating window starting %s: %w", candles[split].Time, err)
+		}
+		if len(equity) == 0 {
+			return nil, fmt.Errorf("evaluating window starting %s returned no equity", candles[split].Time)
+		}
+		if equity[0].Balance <= 0 {
+			return nil, fmt.Errorf("window starting %s has a starting balance of %g", candles[split].Time, equity[0].Balance)
+		}
+
+		window := WalkForwardWindow{
+			InSampleStart:    candles[inStart].Time,
+			OutOfSampleStart: candles[split].Time,
+			OutOfSampleEnd:   candles[end-1].Time,
+			Params:           params,
+			Equity:           equity,
+			Return:           equity[len(equity)-1].Balance/equity[0].Balance - 1,
+			MaxDrawdown:      maxDrawdown(equity),
+		}
+		if window.Return > 0 {
+			result.PositiveWindows++
+		}
+		result.Windows = append(result.Windows, window)
+		result.Equity = chainEquity(result.Equity, equity)
+	}
+
+	first, last := result.Equity[0].Balance, result.Equity[len(result.Equity)-1].Balance
+	result.TotalReturn = last/first - 1
+	result.MaxDrawdown = maxDrawdown(result.Equity)
+	if cfg.PeriodsPerYear > 0 {
+		sharpe, err := SharpeRatio(result.Equity, 0, cfg.PeriodsPerYear)
+		if err != nil {
+			return nil, err
+		}
+		result.Sharpe = sharpe
+	}
+	return &result, nil
+}
+
+// chainEquity appends next to curve, scaled so that next's first point
+// lands on curve's last balance and replaces it.
+func chainEquity(curve, next []EquityPoint) []EquityPoint {
+	if len(curve) == 0 {
+		return append(curve, next...)
+	}
+
+	scale := curve[len(curve)-1].Balance / next[0].Balance
+	curve = curve[:len(curve)-1]
+	for _, point := range next {
+		point.Balance *= scale
+		curve = append(curve, point)
+	}
+	return curve
+}
+
+func maxDrawdown(equity []EquityPoint) float64 {
+	var peak, drawdown float64
+	for _, point := range equity {
+		peak = math.Max(peak, point.Balance)
+		if peak > 0 {
+			drawdown = math.Max(drawdown, (peak-point.Balance)/peak)
+		}
+	}
+	return drawdown
+}
@@ -0,0 +1,113 @@
+package trader
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+)
+
+// Watchlist is a named set of instruments, defined in config.json as
+//
+//	"watchlists": {"majors": ["EUR_USD", "GBP_USD", "USD_JPY"]}
+//
+// or with WithWatchlists.
+type Watchlist struct {
+	Name        string
+	Instruments []string
+}
+
+// WithWatchlists replaces the watchlists loaded from config.json.
+func WithWatchlists(watchlists map[string][]string) Option {
+	return func(c *Client) {
+		c.watchlists = maps.Clone(watchlists)
+	}
+}
+
+// ValidateWatchlists checks every watchlist member is an instrument the
+// account can trade. It runs automatically the first time a watchlist is
+// used; call it straight after NewClient to catch typos at startup.
+func (c *Client) ValidateWatchlists() error {
+	seen := make(map[string]bool)
+	var members []string
+	for _, instruments := range c.watchlists {
+		for _, instrument := range instruments {
+			if !seen[instrument] {
+				seen[instrument] = true
+				members = append(members, instrument)
+			}
+		}
+	}
+	if len(members) == 0 {
+		return nil
+	}
+
+	known, err := c.getInstruments(members)
+	if err != nil {
+		return fmt.Errorf("validating watchlists: %w", err)
+	}
+	for _, instrument := range known {
+		delete(seen, instrument.Name)
+	}
+	if len(seen) > 0 {
+		unknown := slices.Collect(maps.Keys(seen))
+		sort.Strings(unknown)
+		return fmt.Errorf("watchlists contain unknown instruments: %v", unknown)
+	}
+
+	c.mu.Lock()
+	c.watchlistsValidated = true
+	c.mu.Unlock()
+	return nil
+}
+
+// Watchlist returns the watchlist called name.
+func (c *Client) Watchlist(name string) (*Watchlist, error) {
+	instruments, ok := c.watchlists[name]
+	if !ok {
+		return nil, fmt.Errorf("no watchlist named %q", name)
+	}
+	if len(instruments) == 0 {
+		return nil, fmt.Errorf("watchlist %q is empty", name)
+	}
+
+	c.mu.Lock()
+	validated := c.watchlistsValidated
+	c.mu.Unlock()
+	if !validated {
+		if err := c.ValidateWatchlists(); err != nil {
+			return nil, err
+		}
+	}
+	return &Watchlist{Name: name, Instruments: slices.Clone(instruments)}, nil
+}
+
+// GetWatchlistPrices fetches the current prices of every instrument in the
+// watchlist called name in one request.
+func (c *Client) GetWatchlistPrices(name string) (*PricingResponse, error) {
+	watchlist, err := c.Watchlist(name)
+	if err != nil {
+		return nil, err
+	}
+	return c.getPrices(watchlist.Instruments, PricingOptions{})
+}
+
+// GetWatchlistCandles fetches candles with opts for every instrument in the
+// watchlist called name, keyed by instrument. OANDA serves candles one
+// instrument at a time, so this makes a request per member.
+func (c *Client) GetWatchlistCandles(name string, opts CandleOptions) (map[string]*CandlesResponse, error) {
+	watchlist, err := c.Watchlist(name)
+	if err != nil {
+		return nil, err
+	}
+
+	candles := make(map[string]*CandlesResponse, len(watchlist.Instruments))
+	for _, instrument := range watchlist.Instruments {
+		candlesResponse, err := c.getCandles(instrument, opts)
+		if err != nil {
+			return nil, fmt.Errorf("fetching candles for %s: %w", instrument, err)
+		}
+		candles[instrument] = candlesResponse
+	}
+	return candles, nil
+}